@@ -0,0 +1,186 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package login
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+var (
+	githubOnly bool
+	jiraOnly   bool
+	jiraAuth   string
+	jiraEmail  string
+	appID      int64
+	appInstall int64
+	appKeyFile string
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Save tokens to an encrypted token store",
+		Long: "Prompt for the github and jira tokens and save them to the token file, encrypted with a passphrase.  " +
+			"An empty answer keeps the current token, and a plaintext token store is encrypted.  " +
+			"The passphrase is read from " + config.PassphraseEnvVar + " when set, and tokens may be piped in one per line.  " +
+			"--jira-auth selects how to authenticate to jira: with a personal access token of Jira Server or Data Center (bearer), " +
+			"with the email and API token of a Jira Cloud account (basic), or with the OAuth 2.0 (3LO) tokens of a Jira Cloud app (oauth2).  " +
+			"--github-app-id saves a github app installation, whose private key is kept in the token store, to authenticate instead of " +
+			"a personal github token; entering a github token switches back to it.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+			config := config.NewConfig(ff)
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			tokens, err := config.ReadTokenFile()
+			if err != nil {
+				return err
+			}
+
+			saved := []string{}
+			if !jiraOnly {
+				entered, err := readGithub(tokens)
+				if err != nil {
+					return err
+				}
+				if entered {
+					saved = append(saved, "github")
+				}
+			}
+			if !githubOnly {
+				entered, err := readJira(tokens)
+				if err != nil {
+					return err
+				}
+				if entered {
+					saved = append(saved, "jira")
+				}
+			}
+			if len(saved) == 0 {
+				return errors.New("no tokens entered")
+			}
+
+			err = config.WriteTokenFile(tokens)
+			if err != nil {
+				return err
+			}
+			noun := "token"
+			if len(saved) > 1 {
+				noun = "tokens"
+			}
+			fmt.Printf("saved %s %s to encrypted token store %q\n", strings.Join(saved, " and "), noun, config.TokenFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&githubOnly, "github", false, "only save the github token")
+	cmd.Flags().BoolVar(&jiraOnly, "jira", false, "only save the jira token")
+	cmd.MarkFlagsMutuallyExclusive("github", "jira")
+	cmd.Flags().StringVar(&jiraAuth, "jira-auth", "", "jira auth mode: bearer, basic or oauth2 (default: keep the current mode)")
+	cmd.Flags().StringVar(&jiraEmail, "jira-email", "", "email of the Jira Cloud account, for the basic jira auth mode")
+	cmd.MarkFlagsMutuallyExclusive("github", "jira-auth")
+	cmd.Flags().Int64Var(&appID, "github-app-id", 0, "ID of the github app to authenticate as, instead of a github token")
+	cmd.Flags().Int64Var(&appInstall, "github-app-installation-id", 0, "ID of the installation of the github app")
+	cmd.Flags().StringVar(&appKeyFile, "github-app-key-file", "", "file containing the PEM encoded private key of the github app")
+	cmd.MarkFlagsRequiredTogether("github-app-id", "github-app-installation-id", "github-app-key-file")
+	cmd.MarkFlagsMutuallyExclusive("jira", "github-app-id")
+
+	return cmd
+}
+
+// readGithub prompts for the github token, or saves the selected github app
+func readGithub(tokens *config.TokenPair) (bool, error) {
+	if appID != 0 {
+		key, err := os.ReadFile(appKeyFile)
+		if err != nil {
+			return false, err
+		}
+		tokens.GithubApp = &config.GithubApp{AppID: appID, InstallationID: appInstall, PrivateKey: string(key)}
+		return true, tokens.GithubApp.Validate()
+	}
+
+	token, err := util.ReadSecret("Github token (empty keeps the current one): ")
+	if err != nil {
+		return false, err
+	}
+	if len(token) == 0 {
+		return false, nil
+	}
+	tokens.GithubToken = string(token)
+	tokens.GithubApp = nil
+	return true, nil
+}
+
+// readJira prompts for the jira token, or the oauth2 settings, of the selected auth mode
+func readJira(tokens *config.TokenPair) (bool, error) {
+	switch config.JiraAuthMode(jiraAuth) {
+	case "":
+	case config.JiraAuthBearer:
+		tokens.JiraAuth = nil
+	case config.JiraAuthBasic:
+		tokens.JiraAuth = &config.JiraAuth{Mode: config.JiraAuthBasic, Email: jiraEmail}
+	case config.JiraAuthOAuth2:
+		o := &config.JiraOAuth2{}
+		for _, field := range []struct {
+			prompt string
+			value  *string
+		}{
+			{"Jira OAuth 2.0 client ID: ", &o.ClientID},
+			{"Jira OAuth 2.0 client secret: ", &o.ClientSecret},
+			{"Jira Cloud ID of the site: ", &o.CloudID},
+			{"Jira OAuth 2.0 refresh token: ", &o.RefreshToken},
+		} {
+			value, err := util.ReadSecret(field.prompt)
+			if err != nil {
+				return false, err
+			}
+			*field.value = string(value)
+		}
+		tokens.JiraAuth = &config.JiraAuth{Mode: config.JiraAuthOAuth2, OAuth2: o}
+		return true, tokens.JiraAuth.Validate()
+	default:
+		return false, fmt.Errorf("invalid jira auth mode %q (accepted modes are %q, %q, %q)", jiraAuth, config.JiraAuthBearer, config.JiraAuthBasic, config.JiraAuthOAuth2)
+	}
+	if err := tokens.JiraAuth.Validate(); err != nil {
+		return false, err
+	}
+
+	prompt := "Jira token (empty keeps the current one): "
+	if tokens.JiraAuth.GetMode() == config.JiraAuthBasic {
+		prompt = fmt.Sprintf("Jira API token of %s (empty keeps the current one): ", tokens.JiraAuth.Email)
+	}
+	token, err := util.ReadSecret(prompt)
+	if err != nil {
+		return false, err
+	}
+	if len(token) > 0 {
+		tokens.JiraToken = string(token)
+		return true, nil
+	}
+	return jiraAuth != "", nil
+}
@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logout
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+var (
+	githubOnly bool
+	jiraOnly   bool
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove tokens from the token store",
+		Long:  "Delete the token file, or remove a single token from it, keeping its format.  Tokens from the environment or a credential helper are left alone.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+			config := config.NewConfig(ff)
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			deleted := true
+			if githubOnly || jiraOnly {
+				deleted, err = config.RemoveTokens(removed())
+			} else {
+				err = config.RemoveTokenFile()
+			}
+			if errors.Is(err, fs.ErrNotExist) {
+				fmt.Printf("no token store %q\n", config.TokenFile)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !deleted {
+				fmt.Printf("removed %s token from token store %q\n", removed(), config.TokenFile)
+				return nil
+			}
+			fmt.Printf("deleted token store %q\n", config.TokenFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&githubOnly, "github", false, "only remove the github token or app")
+	cmd.Flags().BoolVar(&jiraOnly, "jira", false, "only remove the jira token and auth settings")
+	cmd.MarkFlagsMutuallyExclusive("github", "jira")
+
+	return cmd
+}
+
+// removed returns the kind of credentials which --github or --jira removes
+func removed() config.TokenKind {
+	if githubOnly {
+		return config.TokenGithub
+	}
+	return config.TokenJira
+}
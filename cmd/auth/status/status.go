@@ -0,0 +1,133 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show where each token comes from",
+		Long:  "Show the token store and the source each token is found in, in order of precedence, without revealing the tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+			cfg := config.NewConfig(ff)
+			err = cfg.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("token store %q: %s\n", cfg.TokenFile, storeFormat(cfg.TokenFile))
+			fmt.Println(lookupGithub(cfg.TokenSources))
+			auth, err := cfg.TokenSources.JiraAuth()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("jira auth: %s\n", describeJiraAuth(auth))
+			if auth.GetMode() != config.JiraAuthOAuth2 {
+				fmt.Printf("jira token: %s\n", lookup(cfg.TokenSources, config.TokenJira))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func storeFormat(file string) string {
+	encrypted, err := config.IsEncryptedTokenStore(file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "missing"
+	case err != nil:
+		return fmt.Sprintf("unreadable (%v)", err)
+	case encrypted:
+		return "encrypted"
+	}
+	return "plaintext"
+}
+
+// lookup describes the first source having the token
+func lookup(sources config.TokenChain, kind config.TokenKind) string {
+	for _, s := range sources {
+		token, err := s.Token(kind)
+		if err != nil {
+			return fmt.Sprintf("error from %s: %v", s, err)
+		}
+		if token != "" {
+			return fmt.Sprintf("%s from %s", mask(token), s)
+		}
+	}
+	return fmt.Sprintf("missing (looked in: %s)", sources)
+}
+
+// lookupGithub describes the github app or token of the first source having either, as TokenChain.Github
+// picks them
+func lookupGithub(sources config.TokenChain) string {
+	for _, s := range sources {
+		if as, ok := s.(config.GithubAppSource); ok {
+			app, err := as.GithubApp()
+			if err != nil {
+				return fmt.Sprintf("github app: error from %s: %v", s, err)
+			}
+			if app != nil {
+				return fmt.Sprintf("github app: app %d, installation %d from %s", app.AppID, app.InstallationID, s)
+			}
+		}
+		token, err := s.Token(config.TokenGithub)
+		if err != nil {
+			return fmt.Sprintf("github token: error from %s: %v", s, err)
+		}
+		if token != "" {
+			return fmt.Sprintf("github token: %s from %s", mask(token), s)
+		}
+	}
+	return fmt.Sprintf("github token: missing (looked in: %s)", sources)
+}
+
+// mask hides all but the last characters of longer tokens
+func mask(token string) string {
+	if len(token) < 12 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", 8) + token[len(token)-4:]
+}
+
+func describeJiraAuth(auth *config.JiraAuth) string {
+	switch auth.GetMode() {
+	case config.JiraAuthBasic:
+		return fmt.Sprintf("%s, as %s", config.JiraAuthBasic, auth.Email)
+	case config.JiraAuthOAuth2:
+		expiry := "unknown expiry"
+		if !auth.OAuth2.Expiry.IsZero() {
+			expiry = "access token expires " + auth.OAuth2.Expiry.Local().Format(time.RFC3339)
+		}
+		return fmt.Sprintf("%s, client %s on cloud site %s (%s)", config.JiraAuthOAuth2, auth.OAuth2.ClientID, auth.OAuth2.CloudID, expiry)
+	}
+	return string(config.JiraAuthBearer)
+}
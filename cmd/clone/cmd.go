@@ -13,8 +13,10 @@
 package clone
 
 import (
+	"fmt"
 	"strconv"
 
+	"github.com/google/go-github/v60/github"
 	"github.com/spf13/cobra"
 
 	"github.com/oceanc80/gh2jira/pkg/config"
@@ -24,7 +26,13 @@ import (
 )
 
 var (
-	dryRun bool
+	dryRun     bool
+	onExisting string
+	milestone  string
+	assignee   string
+	label      []string
+	state      string
+	workers    int
 )
 
 func NewCmd() *cobra.Command {
@@ -32,8 +40,22 @@ func NewCmd() *cobra.Command {
 		Use:   "clone <ISSUE_ID> [ISSUE_ID ...]",
 		Short: "Clone given Github issues to Jira",
 		Long: `Clone given Github issues to Jira.
+Issues are either given by number or selected with the same filters as 'github list'
+(--milestone, --assignee, --label, --state); pull requests matching a filter are skipped.
 WARNING! This will write to your jira instance. Use --dryrun to see what will happen`,
-		Args: cobra.MinimumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			filtered := cmd.Flags().Changed("milestone") || cmd.Flags().Changed("assignee") ||
+				cmd.Flags().Changed("label") || cmd.Flags().Changed("state")
+			switch {
+			case len(args) == 0 && !filtered:
+				return fmt.Errorf("requires at least one issue id or a filter flag")
+			case len(args) > 0 && filtered:
+				return fmt.Errorf("issue ids cannot be combined with filter flags")
+			}
+			// fail before the lookup of existing clones, which scans the whole jira project
+			_, err := jira.ParseExistingAction(onExisting)
+			return err
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			ff, err := util.NewFlagFeeder(cmd)
@@ -46,8 +68,20 @@ WARNING! This will write to your jira instance. Use --dryrun to see what will ha
 			if err != nil {
 				return err
 			}
+			err = config.RequireGithubToken()
+			if err != nil {
+				return err
+			}
+			err = config.RequireJiraToken()
+			if err != nil {
+				return err
+			}
 
-			gc, err := gh.NewConnection(gh.WithContext(cmd.Context()), gh.WithToken(config.Tokens.GithubToken))
+			gc, err := gh.NewConnection(
+				gh.WithContext(cmd.Context()),
+				gh.WithAuth(config.Tokens),
+				gh.WithEnterpriseURLs(config.GithubBaseURL, config.GithubUploadURL),
+			)
 			if err != nil {
 				return err
 			}
@@ -58,7 +92,7 @@ WARNING! This will write to your jira instance. Use --dryrun to see what will ha
 
 			jc, err := jira.NewConnection(
 				jira.WithBaseURI(config.JiraBaseUrl),
-				jira.WithAuthToken(config.Tokens.JiraToken),
+				jira.WithAuth(config.Tokens, config.SaveJiraAuth),
 			)
 			if err != nil {
 				return err
@@ -69,16 +103,56 @@ WARNING! This will write to your jira instance. Use --dryrun to see what will ha
 				return err
 			}
 
-			for _, id := range args {
-				issueId, _ := strconv.Atoi(id)
-				issue, err := gc.GetIssue(issueId, gh.WithProject(config.GithubProject))
+			// look up the issues which were cloned on a previous run so we don't create duplicates
+			clones, err := jc.FindClones(cmd.Context(), config.JiraProject, workers)
+			if err != nil {
+				return err
+			}
+
+			var issues []*github.Issue
+			if len(args) > 0 {
+				for _, id := range args {
+					issueId, err := strconv.Atoi(id)
+					if err != nil {
+						return fmt.Errorf("invalid issue id %q: %v", id, err)
+					}
+					issue, err := gc.GetIssue(issueId, gh.WithProject(config.GithubProject))
+					if err != nil {
+						return err
+					}
+					issues = append(issues, issue)
+				}
+			} else {
+				found, err := gc.ListIssues(
+					gh.WithMilestone(milestone),
+					gh.WithAssignee(assignee),
+					gh.WithProject(config.GithubProject),
+					gh.WithLabels(label...),
+					gh.WithState(state),
+				)
 				if err != nil {
 					return err
 				}
+				for _, issue := range found {
+					if issue.IsPullRequest() {
+						// We have a PR, skipping
+						continue
+					}
+					issues = append(issues, issue)
+				}
+				fmt.Printf("Found %d matching issues in %s\n", len(issues), config.GithubProject)
+			}
 
-				_, err = jc.Clone(issue, config.JiraProject, dryRun)
+			for _, issue := range issues {
+				_, err = jc.Clone(issue, config.JiraProject, dryRun,
+					jira.WithExistingClones(clones),
+					jira.WithOnExisting(jira.ExistingAction(onExisting)),
+					jira.WithFieldMapping(config.CloneMapping),
+					jira.WithUserMapping(config.Users),
+					jira.WithVersionMapping(config.Versions),
+				)
 				if err != nil {
-					return nil
+					return err
 				}
 			}
 			return nil
@@ -86,6 +160,14 @@ WARNING! This will write to your jira instance. Use --dryrun to see what will ha
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dryrun", false, "display what would happen without taking actually doing it")
+	cmd.Flags().StringVar(&onExisting, "on-existing", string(jira.ExistingSkip), "action when the issue already has a jira clone: skip, or update its summary and description, and the fix version, labels, components and assignee set by the mappings")
+	cmd.Flags().StringVar(&milestone, "milestone", "",
+		"clone issues in the milestone ID from the url, not the display name")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "clone issues assigned to username")
+	cmd.Flags().StringSliceVar(&label, "label", nil,
+		"clone issues with label i.e. --label \"documentation,bug\" or --label doc --label bug (default: none)")
+	cmd.Flags().StringVar(&state, "state", "open", "clone issues in state (open, closed, or all)")
+	cmd.Flags().IntVar(&workers, "workers", util.DefaultWorkers, "number of concurrent jira lookups of existing clones")
 
 	return cmd
 }
@@ -24,6 +24,7 @@ var (
 	milestone string
 	assignee  string
 	label     []string
+	state     string
 )
 
 func NewCmd() *cobra.Command {
@@ -38,12 +39,20 @@ func NewCmd() *cobra.Command {
 				return err
 			}
 			config := config.NewConfig(ff)
-			err = config.Read()
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+			err = config.ReadGithubToken()
 			if err != nil {
 				return err
 			}
 
-			gc, err := gh.NewConnection(gh.WithContext(cmd.Context()), gh.WithToken(config.Tokens.GithubToken))
+			gc, err := gh.NewConnection(
+				gh.WithContext(cmd.Context()),
+				gh.WithAuth(config.Tokens),
+				gh.WithEnterpriseURLs(config.GithubBaseURL, config.GithubUploadURL),
+			)
 			if err != nil {
 				return err
 			}
@@ -57,6 +66,7 @@ func NewCmd() *cobra.Command {
 				gh.WithAssignee(assignee),
 				gh.WithProject(config.GithubProject),
 				gh.WithLabels(label...),
+				gh.WithState(state),
 			)
 			if err != nil {
 				return err
@@ -79,6 +89,7 @@ func NewCmd() *cobra.Command {
 	cmd.Flags().StringVar(&assignee, "assignee", "", "username assigned the issue")
 	cmd.Flags().StringSliceVar(&label, "label", nil,
 		"label i.e. --label \"documentation,bug\" or --label doc --label bug (default: none)")
+	cmd.Flags().StringVar(&state, "state", "open", "issue state (open, closed, or all)")
 
 	return cmd
 }
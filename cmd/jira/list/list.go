@@ -38,14 +38,18 @@ func NewCmd() *cobra.Command {
 				return err
 			}
 			config := config.NewConfig(ff)
-			err = config.Read()
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+			err = config.ReadJiraToken()
 			if err != nil {
 				return err
 			}
 
 			jc, err := jira.NewConnection(
 				jira.WithBaseURI(config.JiraBaseUrl),
-				jira.WithAuthToken(config.Tokens.JiraToken),
+				jira.WithAuth(config.Tokens, config.SaveJiraAuth),
 			)
 			if err != nil {
 				return err
@@ -15,22 +15,27 @@ package root
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/oceanc80/gh2jira/cmd/auth"
 	"github.com/oceanc80/gh2jira/cmd/clone"
 	"github.com/oceanc80/gh2jira/cmd/github"
 	"github.com/oceanc80/gh2jira/cmd/jira"
+	"github.com/oceanc80/gh2jira/cmd/workflow"
 )
 
-const defaultTokensFile string = "tokenstore.yaml"
 const defaultProfilesFile string = "profiles.yaml"
-const defaultJiraBaseURL string = "https://issues.redhat.com/"
 
 var (
 	tokensFile   string
+	credHelper   string
 	profilesFile string
 	profileName  string
 	ghProject    string
 	jProject     string
 	jUrl         string
+	ghUrl        string
+	userMapping  string
+	wfFile       string
+	wfName       string
 )
 
 func NewCmd() *cobra.Command {
@@ -45,15 +50,23 @@ func NewCmd() *cobra.Command {
 	cmd.AddCommand(jira.NewCmd())
 	cmd.AddCommand(clone.NewCmd())
 	cmd.AddCommand(NewReconcileCmd())
+	cmd.AddCommand(workflow.NewCmd())
+	cmd.AddCommand(auth.NewCmd())
 
-	cmd.PersistentFlags().StringVar(&tokensFile, "token-file", defaultTokensFile, "file containing authentication tokens, if different than profile")
+	cmd.PersistentFlags().StringVar(&tokensFile, "token-file", "", "file containing authentication tokens, if different than profile (default \"tokenstore.yaml\")")
+	cmd.PersistentFlags().StringVar(&credHelper, "credential-helper", "", "command printing the token named by its last argument (github or jira), if different than profile")
 	cmd.PersistentFlags().StringVar(&profilesFile, "profiles-file", defaultProfilesFile, "filename containing optional profile attributes")
 
 	// profile / project names must not have default values since they will always be used as if they were user-specified values, overriding all default values given
 	cmd.PersistentFlags().StringVar(&profileName, "profile-name", "", "profile name to use (implies profiles-file)")
 	cmd.PersistentFlags().StringVar(&ghProject, "github-project", "", "Github project domain to list if not using a profile, e.g.: operator-framework/operator-sdk")
 	cmd.PersistentFlags().StringVar(&jProject, "jira-project", "", "Jira project if not using a profile, e.g.: OCPBUGS")
-	cmd.PersistentFlags().StringVar(&jUrl, "jira-base-url", defaultJiraBaseURL, "Jira base URL, e.g.: https://issues.redhat.com")
+	cmd.PersistentFlags().StringVar(&jUrl, "jira-base-url", "", "Jira base URL, if different than profile, e.g.: https://issues.redhat.com (default \"https://issues.redhat.com/\")")
+	cmd.PersistentFlags().StringVar(&ghUrl, "github-base-url", "", "Github Enterprise Server base URL, if different than profile, e.g.: https://github.example.com (default: github.com)")
+	cmd.PersistentFlags().StringVar(&userMapping, "user-mapping-file", "", "file mapping github logins to jira users, if different than profile")
+
+	cmd.PersistentFlags().StringVar(&wfFile, "workflow-file", "", "file containing github/jira state mapping workflows, if different than profile (default \"workflows.yaml\")")
+	cmd.PersistentFlags().StringVar(&wfName, "workflow-name", "", "name of the workflow to use, if different than profile (default \"jira\")")
 
 	return cmd
 }
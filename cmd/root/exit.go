@@ -0,0 +1,30 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package root
+
+// exit statuses besides 0 (success) and 1 (the command failed)
+const (
+	// ExitMismatch means reconcile found mismatched or orphaned issues
+	ExitMismatch int = 2
+	// ExitBroken means reconcile found links it could not evaluate
+	ExitBroken int = 3
+)
+
+// ExitError is returned by commands which completed, but whose outcome should be reflected in the exit status
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
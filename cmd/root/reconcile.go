@@ -12,28 +12,38 @@
 package root
 
 import (
-	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/oceanc80/gh2jira/pkg/config"
 	"github.com/oceanc80/gh2jira/pkg/gh"
+	"github.com/oceanc80/gh2jira/pkg/history"
 	"github.com/oceanc80/gh2jira/pkg/jira"
 	"github.com/oceanc80/gh2jira/pkg/reconcile"
+	"github.com/oceanc80/gh2jira/pkg/report"
 	"github.com/oceanc80/gh2jira/pkg/util"
+	"github.com/oceanc80/gh2jira/pkg/workflow"
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/yaml"
 )
 
 var porcelain bool
-var output string = "json"
+var output string
+var fix bool
+var fixDryRun bool
+var fixComment bool
+var direction string
+var orphans bool
+var cloneOrphans bool
+var workers int
+var failOnMismatch bool
+var record bool
+var historyDir string
+var dimensions []string
 
-const (
-	greenStart  string = "\033[32m"
-	yellowStart string = "\033[33m"
-	redStart    string = "\033[31m"
-	colorReset  string = "\033[0m"
-)
+const defaultHistoryDir string = "reconcile-history"
 
 func NewReconcileCmd() *cobra.Command {
 	runCmd := &cobra.Command{
@@ -41,6 +51,10 @@ func NewReconcileCmd() *cobra.Command {
 		Short: "reconcile github and jira issues",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if fixDryRun && !fix && !cloneOrphans {
+				return fmt.Errorf("--dryrun requires --fix or --clone-orphans")
+			}
+
 			ff, err := util.NewFlagFeeder(cmd)
 			if err != nil {
 				return err
@@ -51,13 +65,25 @@ func NewReconcileCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			err = config.RequireGithubToken()
+			if err != nil {
+				return err
+			}
+			err = config.RequireJiraToken()
+			if err != nil {
+				return err
+			}
 
 			if config.JiraProject == "" {
 				return fmt.Errorf("must specify jira project")
 			}
 			jql := fmt.Sprintf("project=%s and status != Closed", config.JiraProject)
 
-			gc, err := gh.NewConnection(gh.WithContext(cmd.Context()), gh.WithToken(config.Tokens.GithubToken))
+			gc, err := gh.NewConnection(
+				gh.WithContext(cmd.Context()),
+				gh.WithAuth(config.Tokens),
+				gh.WithEnterpriseURLs(config.GithubBaseURL, config.GithubUploadURL),
+			)
 			if err != nil {
 				return err
 			}
@@ -66,13 +92,19 @@ func NewReconcileCmd() *cobra.Command {
 				return err
 			}
 
-			if output != "yaml" && output != "json" {
-				return fmt.Errorf("invalid output format %q (accepted formats are 'yaml', 'json')", output)
+			format, err := outputFormat()
+			if err != nil {
+				return err
+			}
+
+			if cloneOrphans && format != report.FormatText {
+				// clone reports its progress on stdout
+				return fmt.Errorf("--clone-orphans requires the text output format")
 			}
 
 			jc, err := jira.NewConnection(
 				jira.WithBaseURI(config.JiraBaseUrl),
-				jira.WithAuthToken(config.Tokens.JiraToken),
+				jira.WithAuth(config.Tokens, config.SaveJiraAuth),
 			)
 			if err != nil {
 				return err
@@ -83,44 +115,123 @@ func NewReconcileCmd() *cobra.Command {
 				return err
 			}
 
-			results, err := reconcile.Reconcile(cmd.Context(), jql, jc, gc)
+			wf, err := workflow.LoadWorkflow(config.WorkflowFile, config.WorkflowName)
+			if err != nil {
+				return err
+			}
+
+			dims, err := reconcile.ParseDimensions(dimensions)
 			if err != nil {
 				return err
 			}
+			// profiles mapping users or milestones care about them; without a mapping, they can't match
+			if !cmd.Flags().Changed("dimensions") {
+				if config.Users != nil {
+					dims = append(dims, reconcile.DimensionAssignee)
+				}
+				if config.Versions != nil {
+					dims = append(dims, reconcile.DimensionMilestone)
+				}
+			}
 
-			if porcelain {
-				b, _ := json.MarshalIndent(results, "", "  ")
-				if output == "json" {
-					fmt.Println(string(b))
-				} else {
-					yamlData, err := yaml.JSONToYAML(b)
+			links, err := linkRecognizer(config.Links, config.GithubBaseURL)
+			if err != nil {
+				return err
+			}
+
+			options := []reconcile.ReconcileOption{
+				reconcile.WithDimensions(dims...),
+				reconcile.WithLinkRecognizer(links),
+				reconcile.WithWorkflow(wf),
+				reconcile.WithUserMapping(config.Users),
+				reconcile.WithVersionMapping(config.Versions),
+				reconcile.WithWorkers(workers),
+			}
+			if orphans || cloneOrphans {
+				options = append(options, reconcile.WithOrphans(config.GithubProject, config.JiraProject))
+			}
+
+			results, err := reconcile.Reconcile(cmd.Context(), jql, jc, gc, options...)
+			if err != nil {
+				return err
+			}
+
+			taken := time.Now()
+			err = report.Render(os.Stdout, format, results,
+				report.WithTitle(fmt.Sprintf("gh2jira reconcile report for %s", config.JiraProject)),
+				report.WithGenerated(taken),
+				report.WithOrphans(orphans || cloneOrphans),
+			)
+			if err != nil {
+				return err
+			}
+
+			// the recorded results are those left once the orphans are cloned and the mismatches fixed
+			recorded := results
+			orphaned := len(results.Orphans)
+			if cloneOrphans {
+				for _, o := range results.Orphans {
+					project, num, err := o.IssueRef()
 					if err != nil {
 						return err
 					}
-					yamlData = append([]byte("---\n"), yamlData...)
-					_, err = os.Stdout.Write(yamlData)
+					issue, err := gc.GetIssue(num, gh.WithProject(project))
+					if err != nil {
+						return err
+					}
+					_, err = jc.Clone(issue, config.JiraProject, fixDryRun,
+						jira.WithFieldMapping(config.CloneMapping),
+						jira.WithUserMapping(config.Users),
+						jira.WithVersionMapping(config.Versions),
+					)
 					if err != nil {
 						return err
 					}
 				}
-			} else {
-				if len(results.Matches) == 0 && len(results.Mismatches) == 0 {
-					fmt.Println("no issues found")
-				} else {
-					fmt.Printf("found %v mismatch / %v match issues\n", len(results.Mismatches), len(results.Matches))
+				if !fixDryRun {
+					orphaned = 0
+					recorded = &reconcile.TypeResults{Matches: results.Matches, Mismatches: results.Mismatches, Broken: results.Broken}
 				}
+			}
 
-				for _, pair := range results.Mismatches {
-					var result string = "MISMATCH"
-					var resultColor string = redStart
-					fmt.Printf("%s%s|(%s)%s\n\tstatus (%q\t| %q)\t%s%s%s assignees(%q\t| %q)\n",
-						yellowStart, pair.Jira.Name, pair.Git.Name, colorReset, pair.Jira.Status, pair.Git.Status, resultColor, result, colorReset, pair.Jira.Assignee, pair.Git.Assignee)
+			unresolved := len(results.Mismatches)
+			if fix {
+				fixes, err := reconcile.Fix(cmd.Context(), results, jc, gc,
+					reconcile.WithDryRun(fixDryRun),
+					reconcile.WithComment(fixComment),
+					reconcile.WithDirection(reconcile.Direction(direction)),
+					reconcile.WithFixWorkflow(wf),
+				)
+				if err != nil {
+					return err
 				}
-				for _, pair := range results.Matches {
-					var result string = "MATCH"
-					var resultColor string = greenStart
-					fmt.Printf("%s%s|(%s)%s\n\tstatus (%q\t| %q)\t%s%s%s assignees(%q\t| %q)\n",
-						yellowStart, pair.Jira.Name, pair.Git.Name, colorReset, pair.Jira.Status, pair.Git.Status, resultColor, result, colorReset, pair.Jira.Assignee, pair.Git.Assignee)
+				// keep reports other than the terminal one parseable
+				w := os.Stdout
+				if format != report.FormatText {
+					w = os.Stderr
+				}
+				report.RenderFixes(w, fixes, fixDryRun)
+				unresolved = unresolvedMismatches(results, fixes)
+				recorded = reconcile.Resolve(recorded, fixes)
+			}
+
+			if record {
+				store, err := history.NewStore(historyDir)
+				if err != nil {
+					return err
+				}
+				path, err := store.Save(history.NewSnapshot(taken, config.JiraProject, config.GithubProject, recorded))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "recorded reconcile snapshot %s\n", path)
+			}
+
+			if failOnMismatch {
+				if err := drift(results, unresolved, orphaned); err != nil {
+					// the report already explains the failure
+					cmd.SilenceUsage = true
+					return err
 				}
 			}
 
@@ -129,7 +240,96 @@ func NewReconcileCmd() *cobra.Command {
 	}
 
 	runCmd.Flags().BoolVar(&porcelain, "porcelain", false, "display output in an easy-to-parse format for scripts")
-	runCmd.Flags().StringVarP(&output, "output", "o", "json", "output format for porcelain display (json or yaml)")
+	runCmd.Flags().StringVarP(&output, "output", "o", "", "output format: text, markdown, html, csv, junit, json or yaml (default text, or json with --porcelain)")
+	runCmd.Flags().BoolVar(&fix, "fix", false, "change mismatched issues to match their source of truth (see --direction)")
+	runCmd.Flags().BoolVar(&fixDryRun, "dryrun", false, "with --fix or --clone-orphans, display the changes without applying them")
+	runCmd.Flags().BoolVar(&fixComment, "comment", false, "with --fix, comment on each transitioned jira issue explaining the change")
+	runCmd.Flags().StringVar(&direction, "direction", string(reconcile.DirectionGithub),
+		"with --fix, the source of truth: 'github' transitions jira issues, 'jira' closes/reopens github issues")
+	runCmd.Flags().BoolVar(&orphans, "orphans", false, "report open github issues of the project which no jira issue links to")
+	runCmd.Flags().StringSliceVar(&dimensions, "dimensions", dimensionNames(reconcile.DefaultDimensions),
+		"comma-separated aspects of each issue pair to compare: state, assignee, title, labels, milestone")
+	runCmd.Flags().IntVar(&workers, "workers", util.DefaultWorkers, "number of concurrent github lookups")
+	runCmd.Flags().BoolVar(&failOnMismatch, "fail-on-mismatch", false,
+		fmt.Sprintf("exit with status %d when mismatched or orphaned issues remain unresolved, %d when broken links are found", ExitMismatch, ExitBroken))
+	runCmd.Flags().BoolVar(&record, "record", false, "record the results, after any fixes and clones, in the history directory, for reconcile diff")
+	runCmd.PersistentFlags().StringVar(&historyDir, "history-dir", defaultHistoryDir, "directory of recorded reconcile results")
+	runCmd.Flags().BoolVar(&cloneOrphans, "clone-orphans", false, "clone orphaned github issues to jira (implies --orphans)")
+
+	runCmd.AddCommand(newReconcileDiffCmd())
 
 	return runCmd
 }
+
+// outputFormat resolves the report format from --output and --porcelain
+func outputFormat() (report.Format, error) {
+	if output == "" {
+		if porcelain {
+			return report.FormatJSON, nil
+		}
+		return report.FormatText, nil
+	}
+	format, err := report.ParseFormat(output)
+	if err != nil {
+		return "", err
+	}
+	if porcelain && !format.IsMachineReadable() {
+		return "", fmt.Errorf("invalid output format %q for --porcelain (accepted formats are 'yaml', 'json')", output)
+	}
+	return format, nil
+}
+
+// unresolvedMismatches counts the mismatches which --fix did not resolve; a fix applied without its comment
+// still resolves a state mismatch, while a dry run resolves nothing, and other dimensions are never fixed
+func unresolvedMismatches(results *reconcile.TypeResults, fixes []reconcile.FixResult) int {
+	unresolved := len(results.Mismatches)
+	for _, f := range fixes {
+		if f.Applied && slices.Equal(f.Pair.Outcomes.Mismatched(), []reconcile.Dimension{reconcile.DimensionState}) {
+			unresolved--
+		}
+	}
+	return unresolved
+}
+
+// drift returns an ExitError describing the unresolved drift between jira and github, or nil if there is none;
+// broken links take precedence since they leave the comparison incomplete
+func drift(results *reconcile.TypeResults, mismatches int, orphans int) error {
+	switch {
+	case len(results.Broken) > 0:
+		return &ExitError{Code: ExitBroken, Err: fmt.Errorf("found %d broken links", len(results.Broken))}
+	case mismatches > 0 || orphans > 0:
+		return &ExitError{Code: ExitMismatch, Err: fmt.Errorf("found %d mismatched and %d orphaned issues", mismatches, orphans)}
+	}
+	return nil
+}
+
+// linkRecognizer recognizes the configured links; by default those are the links to github.com, or to
+// the github enterprise server at githubBaseURL if set
+func linkRecognizer(links *config.LinkConfig, githubBaseURL string) (*gh.LinkRecognizer, error) {
+	options := []gh.LinkOption{}
+	if links != nil && len(links.Hosts) > 0 {
+		options = append(options, gh.WithLinkHosts(links.Hosts...))
+	} else if githubBaseURL != "" {
+		u, err := url.Parse(githubBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, gh.WithLinkHosts(u.Hostname()))
+	}
+	if links != nil && len(links.Kinds) > 0 {
+		kinds := make([]gh.LinkKind, 0, len(links.Kinds))
+		for _, k := range links.Kinds {
+			kinds = append(kinds, gh.LinkKind(k))
+		}
+		options = append(options, gh.WithLinkKinds(kinds...))
+	}
+	return gh.NewLinkRecognizer(options...)
+}
+
+func dimensionNames(dims []reconcile.Dimension) []string {
+	names := make([]string, 0, len(dims))
+	for _, d := range dims {
+		names = append(names, string(d))
+	}
+	return names
+}
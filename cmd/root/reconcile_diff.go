@@ -0,0 +1,84 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package root
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/history"
+	"github.com/oceanc80/gh2jira/pkg/report"
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+var diffSince time.Duration
+var diffOutput string
+
+func newReconcileDiffCmd() *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "show what changed between recorded reconcile results",
+		Long: `Compare the latest results recorded by 'reconcile --record' with earlier results: by default the previous
+recording, or with --since the latest recording at least that long before the latest one. Mismatches are reported
+as new, fixed, persistent (with how long they have been drifting), broken or gone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+
+			config := config.NewConfig(ff)
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			if config.JiraProject == "" {
+				return fmt.Errorf("must specify jira project")
+			}
+
+			format, err := report.ParseDiffFormat(diffOutput)
+			if err != nil {
+				return err
+			}
+
+			store, err := history.NewStore(historyDir)
+			if err != nil {
+				return err
+			}
+			snaps, err := store.Snapshots(config.JiraProject)
+			if err != nil {
+				return err
+			}
+			baseline, err := history.Baseline(snaps, diffSince)
+			if err != nil {
+				return fmt.Errorf("%v; found %d for project %s in %q", err, len(snaps), config.JiraProject, historyDir)
+			}
+			diff, err := history.Compare(snaps, baseline)
+			if err != nil {
+				return err
+			}
+
+			return report.RenderDiff(os.Stdout, format, diff)
+		},
+	}
+
+	runCmd.Flags().DurationVar(&diffSince, "since", 0, "compare with the results recorded at least this long before the latest, e.g.: 168h for a week (default: the previous recording)")
+	runCmd.Flags().StringVarP(&diffOutput, "output", "o", string(report.FormatText), "output format: text, markdown, json or yaml")
+
+	return runCmd
+}
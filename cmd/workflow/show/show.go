@@ -0,0 +1,111 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/jira"
+	"github.com/oceanc80/gh2jira/pkg/util"
+	"github.com/oceanc80/gh2jira/pkg/workflow"
+)
+
+var (
+	statuses bool
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the selected workflow",
+		Long:  "Show the workflow selected by the profile or flags, optionally with the github state each status of the jira project resolves to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+			config := config.NewConfig(ff)
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			file := config.WorkflowFile
+			if file == "" {
+				file = workflow.DefaultWorkflowFile
+			}
+			wf, err := workflow.LoadWorkflow(file, config.WorkflowName)
+			if err != nil {
+				return err
+			}
+
+			b, err := json.Marshal(wf)
+			if err != nil {
+				return err
+			}
+			yamlData, err := yaml.JSONToYAML(b)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("# workflow %q from %q\n", wf.Name, file)
+			fmt.Print(string(yamlData))
+
+			if !statuses {
+				return nil
+			}
+			err = config.ReadJiraToken()
+			if err != nil {
+				return err
+			}
+
+			jc, err := jira.NewConnection(
+				jira.WithBaseURI(config.JiraBaseUrl),
+				jira.WithAuth(config.Tokens, config.SaveJiraAuth),
+			)
+			if err != nil {
+				return err
+			}
+			err = jc.Connect()
+			if err != nil {
+				return err
+			}
+
+			projectStatuses, err := jc.GetProjectStatuses(cmd.Context(), config.JiraProject)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n# statuses of jira project %s\n", config.JiraProject)
+			for i := range projectStatuses {
+				s := &projectStatuses[i]
+				js := workflow.JiraStatus{Name: s.Name, Category: jira.StatusCategory(s)}
+				ghstate, err := wf.GithubState(js)
+				if err != nil {
+					fmt.Printf("%s (%s) -> %v\n", js.Name, js.Category, err)
+					continue
+				}
+				fmt.Printf("%s (%s) -> %s\n", js.Name, js.Category, ghstate)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&statuses, "statuses", false, "also show the github state of each status of the jira project")
+	return cmd
+}
@@ -0,0 +1,128 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/jira"
+	"github.com/oceanc80/gh2jira/pkg/util"
+	"github.com/oceanc80/gh2jira/pkg/workflow"
+)
+
+var (
+	offline bool
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a workflow",
+		Long: `Validate the selected workflow: check the workflow file's schema, look for duplicate or impossible mappings, and
+compare the mapped jira states with the statuses of the jira project so that typos are caught before reconcile
+reports them as mismatches, and with the transitions of the project's workflows so that statuses which reconcile --fix
+can't move to a mapped state are reported`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ff, err := util.NewFlagFeeder(cmd)
+			if err != nil {
+				return err
+			}
+			config := config.NewConfig(ff)
+			err = config.ReadSettings()
+			if err != nil {
+				return err
+			}
+
+			file := config.WorkflowFile
+			if file == "" {
+				file = workflow.DefaultWorkflowFile
+			}
+			wf, err := workflow.LoadWorkflow(file, config.WorkflowName)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("validating workflow %q from %q\n", wf.Name, file)
+
+			problems := wf.Lint()
+
+			if !offline {
+				err = config.ReadJiraToken()
+				if err != nil {
+					return err
+				}
+
+				jc, err := jira.NewConnection(
+					jira.WithBaseURI(config.JiraBaseUrl),
+					jira.WithAuth(config.Tokens, config.SaveJiraAuth),
+				)
+				if err != nil {
+					return err
+				}
+				err = jc.Connect()
+				if err != nil {
+					return err
+				}
+
+				statuses, err := jc.GetProjectStatuses(cmd.Context(), config.JiraProject)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("comparing with %d statuses of jira project %s\n", len(statuses), config.JiraProject)
+				jstatuses := make([]workflow.JiraStatus, 0, len(statuses))
+				for i := range statuses {
+					jstatuses = append(jstatuses, workflow.JiraStatus{Name: statuses[i].Name, Category: jira.StatusCategory(&statuses[i])})
+				}
+				problems = append(problems, wf.Check(jstatuses)...)
+
+				transitions, err := jc.GetProjectTransitions(cmd.Context(), config.JiraProject)
+				if err != nil {
+					// reading workflows requires the permission to administer jira, which reconcile doesn't need
+					problems = append(problems, workflow.Problem{
+						Severity: workflow.SeverityWarning,
+						Message:  fmt.Sprintf("transitions not checked: %v", err),
+					})
+				} else {
+					fmt.Printf("comparing with %d transitions of the workflows of jira project %s\n", len(transitions), config.JiraProject)
+					jtransitions := make([]workflow.JiraTransition, 0, len(transitions))
+					for _, t := range transitions {
+						jtransitions = append(jtransitions, workflow.JiraTransition{From: t.From, To: t.To})
+					}
+					problems = append(problems, wf.CheckTransitions(jstatuses, jtransitions)...)
+				}
+			}
+
+			errors, warnings := 0, 0
+			for _, p := range problems {
+				fmt.Printf("%s: %s\n", p.Severity, p.Message)
+				if p.Severity == workflow.SeverityError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+			fmt.Printf("found %v errors / %v warnings\n", errors, warnings)
+
+			if errors > 0 {
+				return fmt.Errorf("workflow %q is invalid", wf.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "skip the comparison with the statuses and transitions of the jira project")
+	return cmd
+}
@@ -13,6 +13,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/oceanc80/gh2jira/cmd/root"
@@ -36,6 +37,10 @@ import (
 func main() {
 	cmd := root.NewCmd()
 	if err := cmd.Execute(); err != nil {
+		var exitErr *root.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -13,7 +13,9 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/oceanc80/gh2jira/pkg/util"
@@ -24,10 +26,20 @@ const defaultGithubProject string = "operator-framework/operator-sdk"
 const defaultJiraProject string = "OPECO"
 
 type Config struct {
-	GithubProject string
-	JiraProject   string
-	JiraBaseUrl   string
-	Tokens        *TokenPair
+	GithubProject   string
+	JiraProject     string
+	JiraBaseUrl     string
+	GithubBaseURL   string // empty for github.com, see gh.WithEnterpriseURLs
+	GithubUploadURL string // empty for the github base URL
+	CloneMapping    *CloneMapping
+	Versions        *VersionMapping // nil unless the profile maps milestones to versions
+	Links           *LinkConfig     // nil for the default links
+	Users           *UserMapping
+	WorkflowFile    string     // empty for the default workflow file
+	WorkflowName    string     // empty for the default workflow
+	Tokens          *TokenPair // tokens missing from every source are empty, see RequireGithubToken and RequireJiraToken
+	TokenSources    TokenChain
+	TokenFile       string // the token store file of TokenSources
 
 	Flags *util.FlagFeeder
 }
@@ -42,7 +54,21 @@ func NewConfig(ff *util.FlagFeeder) *Config {
 	}
 }
 
+// Read reads the settings and looks up the tokens
 func (c *Config) Read() error {
+	if err := c.ReadSettings(); err != nil {
+		return err
+	}
+	tokens, err := c.TokenSources.Tokens()
+	if err != nil {
+		return err
+	}
+	c.Tokens = tokens
+	return nil
+}
+
+// ReadSettings reads the settings, including the token sources, without looking up the tokens
+func (c *Config) ReadSettings() error {
 	// order of precedence for determining the source of operation context:
 	// 1. command line overrides via explicit flags (e.g. 'github-project' over profile[profile-name].github-project)
 	// 2. requested profile
@@ -50,6 +76,8 @@ func (c *Config) Read() error {
 	// 4. defaults
 
 	tokenFile := ""
+	credentialHelper := ""
+	userMappingFile := ""
 
 	if c.Flags.ProfilesFile != "" && c.Flags.ProfileName != "" {
 		b, err := readProfiles(c.Flags.ProfilesFile)
@@ -69,25 +97,50 @@ func (c *Config) Read() error {
 			}
 			c.GithubProject = profile.GithubConfig.Project
 			c.JiraProject = profile.JiraConfig.Project
+			if profile.JiraConfig.BaseURL != "" {
+				c.JiraBaseUrl = profile.JiraConfig.BaseURL
+			}
+			c.GithubBaseURL = profile.GithubConfig.BaseURL
+			c.GithubUploadURL = profile.GithubConfig.UploadURL
+			c.CloneMapping = profile.CloneMapping
+			c.Versions = profile.VersionMapping
+			c.Links = profile.Links
+			if err := c.Versions.Validate(); err != nil {
+				return err
+			}
+			userMappingFile = profile.UserMapping
+			c.WorkflowFile = profile.WorkflowFile
+			// the jira lifecycle names the workflow when the profile has no explicit mapping
+			c.WorkflowName = profile.LifecycleMapping
+			if c.WorkflowName == "" {
+				c.WorkflowName = profile.JiraConfig.Lifecycle
+			}
 
 			tokenFile = profile.TokenStore
-			if tokenFile != "" {
-				c.Tokens, err = readTokens(tokenFile)
-				if err != nil {
-					return err
-				}
-			}
+			credentialHelper = profile.CredentialHelper
 		}
 	}
 
 	if c.Flags.TokenFile != "" {
-		tokens, err := readTokens(c.Flags.TokenFile)
-		if err != nil {
-			return err
-		}
-		c.Tokens = tokens
+		tokenFile = c.Flags.TokenFile
+	}
+	if c.Flags.CredentialHelper != "" {
+		credentialHelper = c.Flags.CredentialHelper
 	}
 
+	// tokens are looked up in the environment first, then by the credential helper, then in the token file
+	c.TokenSources = TokenChain{EnvTokenSource{}}
+	if credentialHelper != "" {
+		c.TokenSources = append(c.TokenSources, &HelperTokenSource{Command: credentialHelper})
+	}
+	if tokenFile != "" {
+		c.TokenSources = append(c.TokenSources, &FileTokenSource{File: tokenFile})
+	} else {
+		tokenFile = DefaultTokenFile
+		c.TokenSources = append(c.TokenSources, &FileTokenSource{File: tokenFile, Optional: true})
+	}
+	c.TokenFile = tokenFile
+
 	if c.Flags.GithubProject != "" {
 		c.GithubProject = c.Flags.GithubProject
 	}
@@ -100,18 +153,155 @@ func (c *Config) Read() error {
 		c.JiraBaseUrl = c.Flags.JiraBaseURL
 	}
 
+	if c.Flags.GithubBaseURL != "" {
+		// the upload URL of another server doesn't apply
+		if c.Flags.GithubBaseURL != c.GithubBaseURL {
+			c.GithubUploadURL = ""
+		}
+		c.GithubBaseURL = c.Flags.GithubBaseURL
+	}
+
+	if c.Flags.WorkflowFile != "" {
+		c.WorkflowFile = c.Flags.WorkflowFile
+	}
+
+	if c.Flags.WorkflowName != "" {
+		c.WorkflowName = c.Flags.WorkflowName
+	}
+
+	if c.Flags.UserMappingFile != "" {
+		userMappingFile = c.Flags.UserMappingFile
+	}
+	if userMappingFile != "" {
+		users, err := readUserMapping(userMappingFile)
+		if err != nil {
+			return err
+		}
+		c.Users = users
+	}
+
 	return nil
 }
 
-var readTokens = func(filename string) (*TokenPair, error) {
-	rawTokens, err := ReadTokenStore(filename)
+// ReadGithubToken looks up the github token and app after ReadSettings, failing unless either is found;
+// unlike Read, it doesn't run the credential helper for the jira token
+func (c *Config) ReadGithubToken() error {
+	token, app, err := c.TokenSources.Github()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &TokenPair{
-		GithubToken: rawTokens.Tokens.GithubToken,
-		JiraToken:   rawTokens.Tokens.JiraToken,
-	}, nil
+	c.Tokens.GithubToken = token
+	c.Tokens.GithubApp = app
+	return c.RequireGithubToken()
+}
+
+// ReadJiraToken looks up the jira token and auth settings after ReadSettings, failing unless the auth mode
+// has its token
+func (c *Config) ReadJiraToken() error {
+	token, err := c.TokenSources.Token(TokenJira)
+	if err != nil {
+		return err
+	}
+	auth, err := c.TokenSources.JiraAuth()
+	if err != nil {
+		return err
+	}
+	c.Tokens.JiraToken = token
+	c.Tokens.JiraAuth = auth
+	return c.RequireJiraToken()
+}
+
+// RequireGithubToken fails unless a github token was found; a github app has its own tokens
+func (c *Config) RequireGithubToken() error {
+	if c.Tokens.GithubApp != nil {
+		return nil
+	}
+	return c.requireToken(TokenGithub, c.Tokens.GithubToken)
+}
+
+// RequireJiraToken fails unless a jira token was found; the oauth2 jira auth mode has its own tokens
+func (c *Config) RequireJiraToken() error {
+	if c.Tokens.JiraAuth.GetMode() == JiraAuthOAuth2 {
+		return nil
+	}
+	return c.requireToken(TokenJira, c.Tokens.JiraToken)
+}
+
+func (c *Config) requireToken(kind TokenKind, token string) error {
+	if token == "" {
+		return fmt.Errorf("missing required %s token (looked in: %s)", kind, c.TokenSources)
+	}
+	return nil
+}
+
+// ReadTokenFile reads the tokens of the token file alone, which has none if it doesn't exist
+func (c *Config) ReadTokenFile() (*TokenPair, error) {
+	tokens, err := readTokens(c.TokenFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &TokenPair{}, nil
+	}
+	return tokens, err
+}
+
+// WriteTokenFile encrypts the tokens into the token file, see WriteTokens
+func (c *Config) WriteTokenFile(tokens *TokenPair) error {
+	return WriteTokens(c.TokenFile, tokens)
+}
+
+// SaveJiraAuth replaces the jira auth settings of the token file, e.g. with refreshed oauth2 tokens, keeping
+// its format and other tokens
+func (c *Config) SaveJiraAuth(auth *JiraAuth) error {
+	encrypted, err := IsEncryptedTokenStore(c.TokenFile)
+	if err != nil {
+		return err
+	}
+	tokens, err := readTokens(c.TokenFile)
+	if err != nil {
+		return err
+	}
+	tokens.JiraAuth = auth
+	return c.rewriteTokenFile(tokens, encrypted)
+}
+
+// RemoveTokens removes a kind of credentials from the token file, keeping its format and other credentials;
+// the file is deleted once it holds no credentials, which is reported by the result
+func (c *Config) RemoveTokens(kind TokenKind) (bool, error) {
+	encrypted, err := IsEncryptedTokenStore(c.TokenFile)
+	if err != nil {
+		return false, err
+	}
+	tokens, err := readTokens(c.TokenFile)
+	if err != nil {
+		return false, err
+	}
+	tokens.Remove(kind)
+	if tokens.IsEmpty() {
+		return true, c.RemoveTokenFile()
+	}
+	return false, c.rewriteTokenFile(tokens, encrypted)
+}
+
+// rewriteTokenFile writes the tokens back to the token file in the format it was read in
+func (c *Config) rewriteTokenFile(tokens *TokenPair, encrypted bool) error {
+	if encrypted {
+		return WriteTokens(c.TokenFile, tokens)
+	}
+	return writeTokenStore(c.TokenFile, tokens)
+}
+
+// RemoveTokenFile deletes the token file
+func (c *Config) RemoveTokenFile() error {
+	return os.Remove(c.TokenFile)
+}
+
+// overrideable func for mocking ReadTokens
+var readTokens = func(filename string) (*TokenPair, error) {
+	return ReadTokens(filename)
+}
+
+// overrideable func for mocking ReadUserMapping
+var readUserMapping = func(filename string) (*UserMapping, error) {
+	return ReadUserMapping(filename)
 }
 
 // overrideable func for mocking os.ReadFile
@@ -30,7 +30,23 @@ profiles:
   jiraConfig:
     project: TESTY
     lifecycle: agile
+  cloneMapping:
+    issueType: Task
+    summary: "{{ .Title }}"
+    labelRules:
+    - githubLabel: kind/bug
+      issueType: Bug
+      labels:
+      - upstream-bug
+  versionMapping:
+    rules:
+    - milestone: v(\d+\.\d+)(\.\d+)?
+      version: TESTY $1
+  links:
+    hosts: [github.com, github.example.com]
+    kinds: [pull]
   lifecycleMapping: mapping1
+  workflowFile: team-workflows.yaml
   tokensStore: valid_token_file.yaml
 `
 
@@ -56,11 +72,13 @@ profiles:
 )
 
 func TestConfig_Read(t *testing.T) {
+	clearTokenEnv(t)
 
 	tests := []struct {
 		name              string
 		mockTokenReader   func(filename string) (*TokenPair, error)
 		mockProfileReader func(filename string) ([]byte, error)
+		mockUserReader    func(filename string) (*UserMapping, error)
 		flags             *util.FlagFeeder
 		audit             func(t *testing.T, err error, c *Config)
 	}{
@@ -71,7 +89,7 @@ func TestConfig_Read(t *testing.T) {
 			flags: &util.FlagFeeder{
 				ProfilesFile:  "profiles.yaml", // this is defaulted on in cmd/root.go
 				ProfileName:   "",
-				TokenFile:     "tokenstore.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:     "tokenstore.yaml",
 				GithubProject: "",
 				JiraProject:   "",
 			},
@@ -90,7 +108,7 @@ func TestConfig_Read(t *testing.T) {
 			flags: &util.FlagFeeder{
 				ProfilesFile:  "profiles.yaml", // this is defaulted on in cmd/root.go
 				ProfileName:   "test profile",
-				TokenFile:     "tokenstore.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:     "tokenstore.yaml",
 				GithubProject: "",
 				JiraProject:   "",
 			},
@@ -100,6 +118,16 @@ func TestConfig_Read(t *testing.T) {
 				require.Equal(t, "TESTY", c.JiraProject)
 				require.Equal(t, "mock_github_token", c.Tokens.GithubToken)
 				require.Equal(t, "mock_jira_token", c.Tokens.JiraToken)
+				require.NotNil(t, c.CloneMapping)
+				require.Equal(t, "Task", c.CloneMapping.IssueType)
+				require.Equal(t, []LabelRule{{GithubLabel: "kind/bug", IssueType: "Bug", Labels: []string{"upstream-bug"}}}, c.CloneMapping.LabelRules)
+				require.Equal(t, "team-workflows.yaml", c.WorkflowFile)
+				require.Equal(t, "mapping1", c.WorkflowName)
+				require.NotNil(t, c.Versions)
+				version, err := c.Versions.JiraVersion("v1.2.3")
+				require.NoError(t, err)
+				require.Equal(t, "TESTY 1.2", version)
+				require.Equal(t, &LinkConfig{Hosts: []string{"github.com", "github.example.com"}, Kinds: []string{"pull"}}, c.Links)
 			},
 		},
 		{
@@ -109,18 +137,40 @@ func TestConfig_Read(t *testing.T) {
 			flags: &util.FlagFeeder{
 				ProfilesFile:  "profiles.yaml", // this is defaulted on in cmd/root.go
 				ProfileName:   "test profile",
-				TokenFile:     "tokenstore.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:     "tokenstore.yaml",
 				GithubProject: "overridedomain/overrideproject",
 				JiraProject:   "OVER",
+				WorkflowFile:  "override-workflows.yaml",
+				WorkflowName:  "ocpbugs",
 			},
 			audit: func(t *testing.T, err error, c *Config) {
 				require.NoError(t, err)
 				require.Equal(t, "overridedomain/overrideproject", c.GithubProject)
 				require.Equal(t, "OVER", c.JiraProject)
+				require.Equal(t, "override-workflows.yaml", c.WorkflowFile)
+				require.Equal(t, "ocpbugs", c.WorkflowName)
 				require.Equal(t, "mock_github_token", c.Tokens.GithubToken)
 				require.Equal(t, "mock_jira_token", c.Tokens.JiraToken)
 			},
 		},
+		{
+			name:              "successful with user mapping",
+			mockTokenReader:   mockReadTokensSuccess,
+			mockProfileReader: nil,
+			mockUserReader: func(filename string) (*UserMapping, error) {
+				return &UserMapping{Users: []UserMapEntry{{Github: filename, Jira: "jirauser"}}}, nil
+			},
+			flags: &util.FlagFeeder{
+				ProfilesFile:    "profiles.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:       "tokenstore.yaml",
+				UserMappingFile: "users.yaml",
+			},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.NotNil(t, c.Users)
+				require.Equal(t, "users.yaml", c.Users.Users[0].Github)
+			},
+		},
 		{
 			name:              "error reading profiles",
 			mockTokenReader:   nil,
@@ -128,7 +178,7 @@ func TestConfig_Read(t *testing.T) {
 			flags: &util.FlagFeeder{
 				ProfilesFile:  "profiles.yaml", // this is defaulted on in cmd/root.go
 				ProfileName:   "test profile",
-				TokenFile:     "tokenstore.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:     "tokenstore.yaml",
 				GithubProject: "",
 				JiraProject:   "",
 			},
@@ -144,7 +194,7 @@ func TestConfig_Read(t *testing.T) {
 			flags: &util.FlagFeeder{
 				ProfilesFile:  "profiles.yaml", // this is defaulted on in cmd/root.go
 				ProfileName:   "",
-				TokenFile:     "tokenstore.yaml", // this is defaulted on in cmd/root.go
+				TokenFile:     "tokenstore.yaml",
 				GithubProject: "",
 				JiraProject:   "",
 			},
@@ -159,7 +209,81 @@ func TestConfig_Read(t *testing.T) {
 		config := NewConfig(tt.flags)
 		readTokens = tt.mockTokenReader
 		readProfiles = tt.mockProfileReader
+		readUserMapping = tt.mockUserReader
 		err := config.Read()
 		tt.audit(t, err, config)
 	}
 }
+
+func TestConfig_ReadBaseURLs(t *testing.T) {
+	clearTokenEnv(t)
+	defer func(orig func(string) ([]byte, error)) { readProfiles = orig }(readProfiles)
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+	readTokens = mockReadTokensSuccess
+	readProfiles = func(filename string) ([]byte, error) {
+		return []byte(`
+profiles:
+- description: enterprise
+  githubConfig:
+    project: team/repo
+    baseURL: https://github.example.com/
+    uploadURL: https://uploads.github.example.com/
+  jiraConfig:
+    project: TEAM
+    baseURL: https://example.atlassian.net/
+`), nil
+	}
+
+	tests := []struct {
+		name   string
+		flags  *util.FlagFeeder
+		github string
+		upload string
+		jira   string
+	}{
+		{
+			name:  "defaults",
+			flags: &util.FlagFeeder{},
+			jira:  "https://issues.redhat.com/",
+		},
+		{
+			name:   "profile",
+			flags:  &util.FlagFeeder{ProfilesFile: "profiles.yaml", ProfileName: "enterprise"},
+			github: "https://github.example.com/",
+			upload: "https://uploads.github.example.com/",
+			jira:   "https://example.atlassian.net/",
+		},
+		{
+			name: "flags override profile",
+			flags: &util.FlagFeeder{
+				ProfilesFile:  "profiles.yaml",
+				ProfileName:   "enterprise",
+				GithubBaseURL: "https://ghe.example.org/",
+				JiraBaseURL:   "https://jira.example.org/",
+			},
+			github: "https://ghe.example.org/",
+			jira:   "https://jira.example.org/",
+		},
+		{
+			name: "flag naming the profile's server keeps its upload URL",
+			flags: &util.FlagFeeder{
+				ProfilesFile:  "profiles.yaml",
+				ProfileName:   "enterprise",
+				GithubBaseURL: "https://github.example.com/",
+			},
+			github: "https://github.example.com/",
+			upload: "https://uploads.github.example.com/",
+			jira:   "https://example.atlassian.net/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConfig(tt.flags)
+			require.NoError(t, c.Read())
+			require.Equal(t, tt.github, c.GithubBaseURL)
+			require.Equal(t, tt.upload, c.GithubUploadURL)
+			require.Equal(t, tt.jira, c.JiraBaseUrl)
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+const encryptedSchemaName string = "gh2jira.tokenstore.encrypted"
+
+// PassphraseEnvVar holds the passphrase of encrypted token stores, so that they can be used without a terminal
+const PassphraseEnvVar string = "GH2JIRA_PASSPHRASE"
+
+// scrypt parameters recommended for interactive logins
+const (
+	kdfName     string = "scrypt"
+	kdfN        int    = 1 << 15
+	kdfR        int    = 8
+	kdfP        int    = 1
+	kdfSaltSize int    = 16
+	kdfKeySize  int    = 32
+)
+
+// bounds of the scrypt parameters read from a token store, so that a corrupted or crafted store can't make
+// the key derivation take gigabytes of memory or run indefinitely; scrypt takes 128*N*R bytes
+const (
+	kdfMaxN  int = 1 << 20
+	kdfMaxR  int = 32
+	kdfMaxP  int = 16
+	kdfMaxNR int = 1 << 23
+)
+
+// EncryptedTokenStore is a token store whose tokens are sealed with AES-256-GCM, under a key derived
+// from a passphrase with scrypt.  The sealed tokens are the JSON encoding of a TokenPair.
+type EncryptedTokenStore struct {
+	Schema       string    `json:"schema"`
+	KDF          KDFParams `json:"kdf"`
+	Nonce        []byte    `json:"nonce"`
+	SealedTokens []byte    `json:"sealedTokens"`
+}
+
+// KDFParams records how the key of an encrypted token store is derived from its passphrase
+type KDFParams struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// Validate checks that the parameters are within the bounds accepted for opening a token store
+func (p KDFParams) Validate() error {
+	if p.Name != kdfName {
+		return fmt.Errorf("unsupported key derivation function %q (accepted function is %q)", p.Name, kdfName)
+	}
+	if p.N < 2 || p.N > kdfMaxN || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("invalid scrypt parameter N %d: must be a power of two up to %d", p.N, kdfMaxN)
+	}
+	if p.R < 1 || p.R > kdfMaxR {
+		return fmt.Errorf("invalid scrypt parameter r %d: must be between 1 and %d", p.R, kdfMaxR)
+	}
+	if p.P < 1 || p.P > kdfMaxP {
+		return fmt.Errorf("invalid scrypt parameter p %d: must be between 1 and %d", p.P, kdfMaxP)
+	}
+	if p.N*p.R > kdfMaxNR {
+		return fmt.Errorf("invalid scrypt parameters N %d and r %d: would take more than %d MiB", p.N, p.R, kdfMaxNR*128>>20)
+	}
+	return nil
+}
+
+// SealTokens encrypts tokens with a passphrase, using a new salt and nonce
+func SealTokens(tokens *TokenPair, passphrase []byte) (*EncryptedTokenStore, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	s := &EncryptedTokenStore{
+		Schema: encryptedSchemaName,
+		KDF:    KDFParams{Name: kdfName, Salt: make([]byte, kdfSaltSize), N: kdfN, R: kdfR, P: kdfP},
+	}
+	if _, err := rand.Read(s.KDF.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := s.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return nil, err
+	}
+	s.SealedTokens = aead.Seal(nil, s.Nonce, plaintext, []byte(s.Schema))
+	return s, nil
+}
+
+// Open decrypts the tokens with a passphrase
+func (s *EncryptedTokenStore) Open(passphrase []byte) (*TokenPair, error) {
+	if s.Schema != encryptedSchemaName {
+		return nil, fmt.Errorf("invalid schema: %q should be %q", s.Schema, encryptedSchemaName)
+	}
+	if err := s.KDF.Validate(); err != nil {
+		return nil, err
+	}
+	aead, err := s.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	plaintext, err := aead.Open(nil, s.Nonce, s.SealedTokens, []byte(s.Schema))
+	if err != nil {
+		return nil, errors.New("unable to decrypt tokens: wrong passphrase or corrupted token store")
+	}
+	var tokens TokenPair
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, err
+	}
+	if err := tokens.JiraAuth.Validate(); err != nil {
+		return nil, err
+	}
+	if err := tokens.GithubApp.Validate(); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+func (s *EncryptedTokenStore) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, s.KDF.Salt, s.KDF.N, s.KDF.R, s.KDF.P, kdfKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteTokens encrypts tokens into a token store file readable only by its owner.  An encrypted store
+// keeps the passphrase it was read with, while other stores ask for a new one.
+func WriteTokens(f string, tokens *TokenPair) error {
+	pass, ok := passphrases[f]
+	if !ok {
+		var err error
+		pass, err = newPassphrase(f)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := SealTokens(tokens, pass)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(f, b); err != nil {
+		return err
+	}
+	passphrases[f] = pass
+	return nil
+}
+
+// IsEncryptedTokenStore reports whether a token store file is encrypted
+func IsEncryptedTokenStore(f string) (bool, error) {
+	b, err := readFile(f)
+	if err != nil {
+		return false, err
+	}
+	return isEncrypted(b)
+}
+
+func isEncrypted(b []byte) (bool, error) {
+	var header struct {
+		Schema string `json:"schema"`
+	}
+	if err := yaml.Unmarshal(b, &header); err != nil {
+		return false, err
+	}
+	return header.Schema == encryptedSchemaName, nil
+}
+
+// openTokenStore decrypts the contents of an encrypted token store file
+func openTokenStore(f string, b []byte) (*TokenPair, error) {
+	var s EncryptedTokenStore
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	pass, ok := passphrases[f]
+	if !ok {
+		var err error
+		pass, err = passphrase(fmt.Sprintf("Passphrase for %s: ", f))
+		if err != nil {
+			return nil, err
+		}
+	}
+	tokens, err := s.Open(pass)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", f, err)
+	}
+	passphrases[f] = pass
+	return tokens, nil
+}
+
+// passphrases remembers the passphrase of each token store file, so that it is asked for only once
+var passphrases = map[string][]byte{}
+
+// passphrase reads the passphrase from the environment, or else prompts for it
+func passphrase(prompt string) ([]byte, error) {
+	if p := os.Getenv(PassphraseEnvVar); p != "" {
+		return []byte(p), nil
+	}
+	p, err := readSecret(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty passphrase (set %s or enter one)", PassphraseEnvVar)
+	}
+	return p, nil
+}
+
+// newPassphrase reads the passphrase from the environment, or else prompts for it twice
+func newPassphrase(f string) ([]byte, error) {
+	if p := os.Getenv(PassphraseEnvVar); p != "" {
+		return []byte(p), nil
+	}
+	p, err := passphrase(fmt.Sprintf("New passphrase for %s: ", f))
+	if err != nil {
+		return nil, err
+	}
+	confirm, err := readSecret("Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(p, confirm) {
+		return nil, errors.New("passphrases don't match")
+	}
+	return p, nil
+}
+
+// overrideable func for mocking passphrase prompts
+var readSecret = func(prompt string) ([]byte, error) {
+	return util.ReadSecret(prompt)
+}
+
+// overrideable func for mocking os.WriteFile
+var writeFile = func(file string, data []byte) error {
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+	// tighten the permissions of an existing plaintext store
+	return os.Chmod(file, 0o600)
+}
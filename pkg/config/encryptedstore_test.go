@@ -0,0 +1,240 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealTokens(t *testing.T) {
+	tokens := &TokenPair{GithubToken: "github_token", JiraToken: "jira"}
+
+	s, err := SealTokens(tokens, []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, encryptedSchemaName, s.Schema)
+	require.NotContains(t, string(s.SealedTokens), "jira")
+
+	opened, err := s.Open([]byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, tokens, opened)
+
+	_, err = s.Open([]byte("wrong"))
+	require.EqualError(t, err, "unable to decrypt tokens: wrong passphrase or corrupted token store")
+
+	s.SealedTokens[0] ^= 1
+	_, err = s.Open([]byte("passphrase"))
+	require.Error(t, err)
+
+	other, err := SealTokens(tokens, []byte("passphrase"))
+	require.NoError(t, err)
+	require.NotEqual(t, s.KDF.Salt, other.KDF.Salt)
+	require.NotEqual(t, s.Nonce, other.Nonce)
+
+	s.KDF.N = 1 << 30
+	_, err = s.Open([]byte("passphrase"))
+	require.EqualError(t, err, "invalid scrypt parameter N 1073741824: must be a power of two up to 1048576")
+
+	_, err = SealTokens(tokens, nil)
+	require.EqualError(t, err, "empty passphrase")
+}
+
+func TestKDFParams_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   KDFParams
+		expected string
+	}{
+		{name: "defaults", params: KDFParams{Name: kdfName, N: kdfN, R: kdfR, P: kdfP}},
+		{name: "largest N", params: KDFParams{Name: kdfName, N: 1 << 20, R: 8, P: 1}},
+		{
+			name:     "other function",
+			params:   KDFParams{Name: "argon2id", N: kdfN, R: kdfR, P: kdfP},
+			expected: `unsupported key derivation function "argon2id" (accepted function is "scrypt")`,
+		},
+		{
+			name:     "N too large",
+			params:   KDFParams{Name: kdfName, N: 1 << 30, R: kdfR, P: kdfP},
+			expected: "invalid scrypt parameter N 1073741824: must be a power of two up to 1048576",
+		},
+		{
+			name:     "N not a power of two",
+			params:   KDFParams{Name: kdfName, N: 1000, R: kdfR, P: kdfP},
+			expected: "invalid scrypt parameter N 1000: must be a power of two up to 1048576",
+		},
+		{
+			name:     "no r",
+			params:   KDFParams{Name: kdfName, N: kdfN, P: kdfP},
+			expected: "invalid scrypt parameter r 0: must be between 1 and 32",
+		},
+		{
+			name:     "p too large",
+			params:   KDFParams{Name: kdfName, N: kdfN, R: kdfR, P: 1 << 20},
+			expected: "invalid scrypt parameter p 1048576: must be between 1 and 16",
+		},
+		{
+			name:     "too much memory",
+			params:   KDFParams{Name: kdfName, N: 1 << 20, R: 32, P: 1},
+			expected: "invalid scrypt parameters N 1048576 and r 32: would take more than 1024 MiB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestEncryptedTokenStore(t *testing.T) {
+	defer func(orig func(string) ([]byte, error)) { readFile = orig }(readFile)
+	defer func(orig func(string, []byte) error) { writeFile = orig }(writeFile)
+	defer func(orig func(string) ([]byte, error)) { readSecret = orig }(readSecret)
+
+	type prompt struct {
+		prompt string
+		answer string
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		prompts []prompt
+		write   *TokenPair
+		audit   func(t *testing.T, files map[string][]byte)
+	}{
+		{
+			name:  "passphrase from the environment",
+			env:   "from env",
+			write: &TokenPair{GithubToken: "github_token", JiraToken: "jira"},
+			audit: func(t *testing.T, files map[string][]byte) {
+				encrypted, err := IsEncryptedTokenStore("tokens.yaml")
+				require.NoError(t, err)
+				require.True(t, encrypted)
+
+				delete(passphrases, "tokens.yaml")
+				tokens, err := ReadTokens("tokens.yaml")
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "github_token", JiraToken: "jira"}, tokens)
+			},
+		},
+		{
+			name: "new passphrase is confirmed, then asked for once",
+			prompts: []prompt{
+				{`New passphrase for tokens.yaml: `, "prompted"},
+				{`Confirm passphrase: `, "prompted"},
+				{`Passphrase for tokens.yaml: `, "prompted"},
+			},
+			write: &TokenPair{GithubToken: "github_token"},
+			audit: func(t *testing.T, files map[string][]byte) {
+				delete(passphrases, "tokens.yaml")
+				tokens, err := ReadTokens("tokens.yaml")
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "github_token"}, tokens)
+
+				tokens, err = ReadTokens("tokens.yaml")
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "github_token"}, tokens)
+			},
+		},
+		{
+			name: "wrong passphrase",
+			prompts: []prompt{
+				{`New passphrase for tokens.yaml: `, "right"},
+				{`Confirm passphrase: `, "right"},
+				{`Passphrase for tokens.yaml: `, "wrong"},
+			},
+			write: &TokenPair{GithubToken: "github_token"},
+			audit: func(t *testing.T, files map[string][]byte) {
+				delete(passphrases, "tokens.yaml")
+				_, err := ReadTokens("tokens.yaml")
+				require.EqualError(t, err, "tokens.yaml: unable to decrypt tokens: wrong passphrase or corrupted token store")
+				require.NotContains(t, passphrases, "tokens.yaml")
+			},
+		},
+		{
+			name: "plaintext token store",
+			audit: func(t *testing.T, files map[string][]byte) {
+				files["tokens.yaml"] = []byte("schema: gh2jira.tokenstore\nauthTokens:\n  jira: jira\n")
+				encrypted, err := IsEncryptedTokenStore("tokens.yaml")
+				require.NoError(t, err)
+				require.False(t, encrypted)
+
+				tokens, err := ReadTokens("tokens.yaml")
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{JiraToken: "jira"}, tokens)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PassphraseEnvVar, tt.env)
+			passphrases = map[string][]byte{}
+			files := map[string][]byte{}
+			readFile = func(file string) ([]byte, error) {
+				return files[file], nil
+			}
+			writeFile = func(file string, data []byte) error {
+				files[file] = data
+				return nil
+			}
+			prompts := tt.prompts
+			readSecret = func(p string) ([]byte, error) {
+				require.NotEmpty(t, prompts, "unexpected prompt %q", p)
+				require.Equal(t, prompts[0].prompt, p)
+				answer := prompts[0].answer
+				prompts = prompts[1:]
+				return []byte(answer), nil
+			}
+
+			if tt.write != nil {
+				require.NoError(t, WriteTokens("tokens.yaml", tt.write))
+				require.NotContains(t, string(files["tokens.yaml"]), "github_token")
+			}
+			tt.audit(t, files)
+			require.Empty(t, prompts)
+		})
+	}
+}
+
+func TestNewPassphrase(t *testing.T) {
+	defer func(orig func(string) ([]byte, error)) { readSecret = orig }(readSecret)
+	t.Setenv(PassphraseEnvVar, "")
+
+	answers := [][]byte{[]byte("one"), []byte("two")}
+	readSecret = func(string) ([]byte, error) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	_, err := newPassphrase("tokens.yaml")
+	require.EqualError(t, err, "passphrases don't match")
+
+	readSecret = func(string) ([]byte, error) {
+		return nil, nil
+	}
+	_, err = newPassphrase("tokens.yaml")
+	require.EqualError(t, err, "empty passphrase (set GH2JIRA_PASSPHRASE or enter one)")
+
+	readSecret = func(string) ([]byte, error) {
+		return nil, errors.New("unable to read secret from stdin: EOF")
+	}
+	_, err = passphrase("Passphrase: ")
+	require.EqualError(t, err, "unable to read secret from stdin: EOF")
+}
@@ -0,0 +1,54 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GithubApp identifies the installation of a github app which authenticates instead of the github token,
+// so that automation isn't tied to a personal account and its rate limit
+type GithubApp struct {
+	AppID          int64 `json:"appID"`
+	InstallationID int64 `json:"installationID"`
+	// PrivateKey is the PEM encoded private key of the app; PrivateKeyFile names a file holding it instead
+	PrivateKey     string `json:"privateKey,omitempty"`
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+}
+
+// Validate checks that the app, its installation and its private key are set; a nil app is valid
+func (a *GithubApp) Validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.AppID <= 0 || a.InstallationID <= 0 {
+		return errors.New("github app requires an appID and an installationID")
+	}
+	if (a.PrivateKey == "") == (a.PrivateKeyFile == "") {
+		return errors.New("github app requires either a privateKey or a privateKeyFile")
+	}
+	return nil
+}
+
+// Key returns the PEM encoded private key of the app
+func (a *GithubApp) Key() ([]byte, error) {
+	if a.PrivateKey != "" {
+		return []byte(a.PrivateKey), nil
+	}
+	b, err := readFile(a.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read github app private key: %v", err)
+	}
+	return b, nil
+}
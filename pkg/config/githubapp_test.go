@@ -0,0 +1,80 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubApp(t *testing.T) {
+	defer func(orig func(string) ([]byte, error)) { readFile = orig }(readFile)
+	readFile = func(file string) ([]byte, error) {
+		if file == "app.pem" {
+			return []byte("key from file"), nil
+		}
+		return nil, errors.New("no such file")
+	}
+
+	tests := []struct {
+		name     string
+		app      *GithubApp
+		key      string
+		errMatch string
+	}{
+		{name: "inline key", app: &GithubApp{AppID: 1, InstallationID: 2, PrivateKey: "inline key"}, key: "inline key"},
+		{name: "key file", app: &GithubApp{AppID: 1, InstallationID: 2, PrivateKeyFile: "app.pem"}, key: "key from file"},
+		{
+			name:     "unreadable key file",
+			app:      &GithubApp{AppID: 1, InstallationID: 2, PrivateKeyFile: "missing.pem"},
+			errMatch: "unable to read github app private key: no such file",
+		},
+		{
+			name:     "missing installation",
+			app:      &GithubApp{AppID: 1, PrivateKey: "inline key"},
+			errMatch: "github app requires an appID and an installationID",
+		},
+		{
+			name:     "missing key",
+			app:      &GithubApp{AppID: 1, InstallationID: 2},
+			errMatch: "github app requires either a privateKey or a privateKeyFile",
+		},
+		{
+			name:     "both keys",
+			app:      &GithubApp{AppID: 1, InstallationID: 2, PrivateKey: "inline key", PrivateKeyFile: "app.pem"},
+			errMatch: "github app requires either a privateKey or a privateKeyFile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.app.Validate()
+			if err == nil {
+				var key []byte
+				key, err = tt.app.Key()
+				if err == nil {
+					require.Equal(t, tt.key, string(key))
+				}
+			}
+			if tt.errMatch != "" {
+				require.EqualError(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+
+			c := &Config{Tokens: &TokenPair{GithubApp: tt.app}}
+			require.NoError(t, c.RequireGithubToken())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// JiraAuthMode is the way of authenticating to jira
+type JiraAuthMode string
+
+const (
+	// JiraAuthBearer sends the jira token as a personal access token of Jira Server or Data Center
+	JiraAuthBearer JiraAuthMode = "bearer"
+	// JiraAuthBasic sends the email of a Jira Cloud account with the jira token as its API token
+	JiraAuthBasic JiraAuthMode = "basic"
+	// JiraAuthOAuth2 uses the OAuth 2.0 (3LO) tokens of a Jira Cloud app, refreshing them as needed
+	JiraAuthOAuth2 JiraAuthMode = "oauth2"
+)
+
+// JiraAuth selects how to authenticate to jira; it is kept in the token store with the tokens
+type JiraAuth struct {
+	Mode   JiraAuthMode `json:"mode,omitempty"` // defaults to bearer
+	Email  string       `json:"email,omitempty"`
+	OAuth2 *JiraOAuth2  `json:"oauth2,omitempty"`
+}
+
+// JiraOAuth2 holds the credentials of a Jira Cloud OAuth 2.0 (3LO) app and the tokens it was granted
+type JiraOAuth2 struct {
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret"`
+	// CloudID identifies the Jira Cloud site, whose API is reached through https://api.atlassian.com/ex/jira/<cloudID>
+	CloudID      string    `json:"cloudID"`
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// GetMode returns the mode, defaulting to bearer
+func (a *JiraAuth) GetMode() JiraAuthMode {
+	if a == nil || a.Mode == "" {
+		return JiraAuthBearer
+	}
+	return a.Mode
+}
+
+// Validate checks that the mode has the settings it needs; a nil auth is the default bearer mode
+func (a *JiraAuth) Validate() error {
+	if a == nil {
+		return nil
+	}
+	switch a.GetMode() {
+	case JiraAuthBearer:
+	case JiraAuthBasic:
+		if a.Email == "" {
+			return fmt.Errorf("jira auth mode %q requires an email", JiraAuthBasic)
+		}
+	case JiraAuthOAuth2:
+		o := a.OAuth2
+		if o == nil || o.ClientID == "" || o.ClientSecret == "" || o.CloudID == "" {
+			return fmt.Errorf("jira auth mode %q requires oauth2 clientID, clientSecret and cloudID", JiraAuthOAuth2)
+		}
+		if o.AccessToken == "" && o.RefreshToken == "" {
+			return fmt.Errorf("jira auth mode %q requires an oauth2 accessToken or refreshToken", JiraAuthOAuth2)
+		}
+	default:
+		return fmt.Errorf("invalid jira auth mode %q (accepted modes are %q, %q, %q)", a.Mode, JiraAuthBearer, JiraAuthBasic, JiraAuthOAuth2)
+	}
+	return nil
+}
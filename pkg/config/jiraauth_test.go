@@ -0,0 +1,111 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJiraAuth_Validate(t *testing.T) {
+	oauth2 := func(refresh string) *JiraOAuth2 {
+		return &JiraOAuth2{ClientID: "client", ClientSecret: "secret", CloudID: "cloud", RefreshToken: refresh}
+	}
+
+	tests := []struct {
+		name     string
+		auth     *JiraAuth
+		errMatch string
+	}{
+		{name: "no settings"},
+		{name: "default mode", auth: &JiraAuth{}},
+		{name: "basic", auth: &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"}},
+		{name: "basic without email", auth: &JiraAuth{Mode: JiraAuthBasic}, errMatch: `jira auth mode "basic" requires an email`},
+		{name: "oauth2", auth: &JiraAuth{Mode: JiraAuthOAuth2, OAuth2: oauth2("refresh")}},
+		{
+			name:     "oauth2 without app",
+			auth:     &JiraAuth{Mode: JiraAuthOAuth2},
+			errMatch: `jira auth mode "oauth2" requires oauth2 clientID, clientSecret and cloudID`,
+		},
+		{
+			name:     "oauth2 without tokens",
+			auth:     &JiraAuth{Mode: JiraAuthOAuth2, OAuth2: oauth2("")},
+			errMatch: `jira auth mode "oauth2" requires an oauth2 accessToken or refreshToken`,
+		},
+		{
+			name:     "invalid mode",
+			auth:     &JiraAuth{Mode: "kerberos"},
+			errMatch: `invalid jira auth mode "kerberos" (accepted modes are "bearer", "basic", "oauth2")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.Validate()
+			if tt.errMatch != "" {
+				require.EqualError(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfig_SaveJiraAuth(t *testing.T) {
+	defer func(orig func(string) ([]byte, error)) { readFile = orig }(readFile)
+	defer func(orig func(string, []byte) error) { writeFile = orig }(writeFile)
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+	readTokens = ReadTokens
+
+	files := map[string][]byte{"tokens.yaml": []byte(`
+schema: gh2jira.tokenstore
+authTokens:
+  github: github_token
+  jiraAuth:
+    mode: oauth2
+    oauth2:
+      clientID: client
+      clientSecret: secret
+      cloudID: cloud
+      refreshToken: old
+`)}
+	readFile = func(file string) ([]byte, error) {
+		return files[file], nil
+	}
+	writeFile = func(file string, data []byte) error {
+		files[file] = data
+		return nil
+	}
+
+	c := &Config{TokenFile: "tokens.yaml", Tokens: &TokenPair{GithubToken: "env_github_token"}}
+	tokens, err := ReadTokens("tokens.yaml")
+	require.NoError(t, err)
+	require.Equal(t, JiraAuthOAuth2, tokens.JiraAuth.GetMode())
+	require.Error(t, c.RequireJiraToken())
+	c.Tokens.JiraAuth = tokens.JiraAuth
+	require.NoError(t, c.RequireJiraToken())
+
+	auth := *tokens.JiraAuth
+	o := *auth.OAuth2
+	o.RefreshToken = "rotated"
+	auth.OAuth2 = &o
+	require.NoError(t, c.SaveJiraAuth(&auth))
+
+	encrypted, err := IsEncryptedTokenStore("tokens.yaml")
+	require.NoError(t, err)
+	require.False(t, encrypted)
+	tokens, err = ReadTokens("tokens.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "github_token", tokens.GithubToken)
+	require.Equal(t, "rotated", tokens.JiraAuth.OAuth2.RefreshToken)
+}
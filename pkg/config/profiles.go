@@ -21,14 +21,47 @@ import (
 type DomainConfig struct {
 	Project   string `json:"project"`
 	Lifecycle string `json:"lifecycle"`
+	BaseURL   string `json:"baseURL,omitempty"`   // e.g. of a github enterprise server; default: github.com or the jira base URL flag
+	UploadURL string `json:"uploadURL,omitempty"` // github enterprise server uploads; default: the base URL
+}
+
+// LabelRule maps issues carrying a github label to jira issue fields
+type LabelRule struct {
+	GithubLabel string   `json:"githubLabel"`
+	IssueType   string   `json:"issueType,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Components  []string `json:"components,omitempty"`
+}
+
+// CloneMapping determines the fields of jira issues created by clone.
+// Summary and Description are go text/templates; empty values fall back to the built-in defaults.
+type CloneMapping struct {
+	IssueType   string      `json:"issueType,omitempty"`
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Components  []string    `json:"components,omitempty"`
+	LabelRules  []LabelRule `json:"labelRules,omitempty"`
+}
+
+// LinkConfig selects the remote links of jira issues which reconcile follows to github
+type LinkConfig struct {
+	Hosts []string `json:"hosts,omitempty"` // default: github.com, www.github.com and the github base URL host
+	Kinds []string `json:"kinds,omitempty"` // issues and/or pull, default: both
 }
 
 type Profile struct {
-	Description      string       `json:"description,omitempty"`
-	GithubConfig     DomainConfig `json:"githubConfig"`
-	JiraConfig       DomainConfig `json:"jiraConfig"`
-	LifecycleMapping string       `json:"lifecycleMapping"`
-	TokenStore       string       `json:"tokensStore,omitempty"`
+	Description      string          `json:"description,omitempty"`
+	GithubConfig     DomainConfig    `json:"githubConfig"`
+	JiraConfig       DomainConfig    `json:"jiraConfig"`
+	CloneMapping     *CloneMapping   `json:"cloneMapping,omitempty"`
+	VersionMapping   *VersionMapping `json:"versionMapping,omitempty"`
+	Links            *LinkConfig     `json:"links,omitempty"`
+	LifecycleMapping string          `json:"lifecycleMapping"` // name of the workflow in the workflow file
+	WorkflowFile     string          `json:"workflowFile,omitempty"`
+	TokenStore       string          `json:"tokensStore,omitempty"`
+	CredentialHelper string          `json:"credentialHelper,omitempty"`
+	UserMapping      string          `json:"userMapping,omitempty"`
 }
 
 type Profiles struct {
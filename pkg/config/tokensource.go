@@ -0,0 +1,237 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TokenKind names the service a token authenticates to
+type TokenKind string
+
+const (
+	TokenGithub TokenKind = "github"
+	TokenJira   TokenKind = "jira"
+)
+
+// TokenSource looks up authentication tokens
+type TokenSource interface {
+	// Token returns the token of the given kind, or "" if the source doesn't have one
+	Token(kind TokenKind) (string, error)
+	// String describes the source for error messages
+	String() string
+}
+
+// JiraAuthSource is a token source which also holds the jira auth settings
+type JiraAuthSource interface {
+	// JiraAuth returns the jira auth settings, or nil if the source doesn't have them
+	JiraAuth() (*JiraAuth, error)
+}
+
+// GithubAppSource is a token source which also holds the github app settings
+type GithubAppSource interface {
+	// GithubApp returns the github app settings, or nil if the source doesn't have them
+	GithubApp() (*GithubApp, error)
+}
+
+// TokenChain looks up each token in its sources in order; the first source having the token wins
+type TokenChain []TokenSource
+
+func (c TokenChain) Token(kind TokenKind) (string, error) {
+	for _, s := range c {
+		token, err := s.Token(kind)
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			return token, nil
+		}
+	}
+	return "", nil
+}
+
+func (c TokenChain) String() string {
+	names := make([]string, 0, len(c))
+	for _, s := range c {
+		names = append(names, s.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// JiraAuth looks up the jira auth settings in the sources which hold them; the first one having them wins
+func (c TokenChain) JiraAuth() (*JiraAuth, error) {
+	for _, s := range c {
+		if as, ok := s.(JiraAuthSource); ok {
+			auth, err := as.JiraAuth()
+			if err != nil || auth != nil {
+				return auth, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Github looks up the github token or app in the sources; the first source having either wins, and a source
+// having both authenticates as the app
+func (c TokenChain) Github() (string, *GithubApp, error) {
+	for _, s := range c {
+		if as, ok := s.(GithubAppSource); ok {
+			app, err := as.GithubApp()
+			if err != nil || app != nil {
+				return "", app, err
+			}
+		}
+		token, err := s.Token(TokenGithub)
+		if err != nil || token != "" {
+			return token, nil, err
+		}
+	}
+	return "", nil, nil
+}
+
+// Tokens looks up every kind of token and the auth settings; missing tokens are left empty
+func (c TokenChain) Tokens() (*TokenPair, error) {
+	github, app, err := c.Github()
+	if err != nil {
+		return nil, err
+	}
+	jira, err := c.Token(TokenJira)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := c.JiraAuth()
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{GithubToken: github, JiraToken: jira, JiraAuth: auth, GithubApp: app}, nil
+}
+
+// tokenEnvVars lists the environment variables holding each kind of token, in order of precedence
+var tokenEnvVars = map[TokenKind][]string{
+	TokenGithub: {"GH2JIRA_GITHUB_TOKEN", "GITHUB_TOKEN"},
+	TokenJira:   {"GH2JIRA_JIRA_TOKEN", "JIRA_TOKEN"},
+}
+
+// EnvTokenSource reads tokens from environment variables, so that CI secrets needn't be written to disk
+type EnvTokenSource struct{}
+
+func (EnvTokenSource) Token(kind TokenKind) (string, error) {
+	for _, v := range tokenEnvVars[kind] {
+		if token := strings.TrimSpace(os.Getenv(v)); token != "" {
+			return token, nil
+		}
+	}
+	return "", nil
+}
+
+func (EnvTokenSource) String() string {
+	return fmt.Sprintf("environment (%s, %s)",
+		strings.Join(tokenEnvVars[TokenGithub], ", "), strings.Join(tokenEnvVars[TokenJira], ", "))
+}
+
+// HelperTokenSource runs an external credential helper command with the kind of token as its last
+// argument, e.g.: "my-helper --vault team" github.  The helper prints the token on stdout, or nothing
+// if it has none; its stderr is passed through so that it can prompt.
+type HelperTokenSource struct {
+	Command string
+}
+
+func (h *HelperTokenSource) Token(kind TokenKind) (string, error) {
+	args := strings.Fields(h.Command)
+	if len(args) == 0 {
+		return "", errors.New("empty credential helper command")
+	}
+	out, err := runHelper(args[0], append(args[1:], string(kind))...)
+	if err != nil {
+		return "", fmt.Errorf("credential helper %q failed to provide the %s token: %v", h.Command, kind, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (h *HelperTokenSource) String() string {
+	return fmt.Sprintf("credential helper %q", h.Command)
+}
+
+// overrideable func for mocking credential helpers
+var runHelper = func(name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	err := cmd.Run()
+	return stdout.Bytes(), err
+}
+
+// FileTokenSource reads tokens from a token store file, which is read once.
+// An optional file which doesn't exist has no tokens.
+type FileTokenSource struct {
+	File     string
+	Optional bool
+
+	tokens *TokenPair
+}
+
+func (f *FileTokenSource) Token(kind TokenKind) (string, error) {
+	if err := f.read(); err != nil {
+		return "", err
+	}
+	switch kind {
+	case TokenGithub:
+		return f.tokens.GithubToken, nil
+	case TokenJira:
+		return f.tokens.JiraToken, nil
+	}
+	return "", nil
+}
+
+// JiraAuth returns the jira auth settings of the token store
+func (f *FileTokenSource) JiraAuth() (*JiraAuth, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.tokens.JiraAuth, nil
+}
+
+// GithubApp returns the github app settings of the token store
+func (f *FileTokenSource) GithubApp() (*GithubApp, error) {
+	if err := f.read(); err != nil {
+		return nil, err
+	}
+	return f.tokens.GithubApp, nil
+}
+
+func (f *FileTokenSource) read() error {
+	if f.tokens != nil {
+		return nil
+	}
+	tokens, err := readTokens(f.File)
+	switch {
+	case err != nil && f.Optional && errors.Is(err, fs.ErrNotExist):
+		tokens = &TokenPair{}
+	case err != nil:
+		return err
+	}
+	f.tokens = tokens
+	return nil
+}
+
+func (f *FileTokenSource) String() string {
+	return fmt.Sprintf("token file %q", f.File)
+}
@@ -0,0 +1,294 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+// clearTokenEnv keeps tokens from the environment running the tests out of them
+func clearTokenEnv(t *testing.T) {
+	for _, vars := range tokenEnvVars {
+		for _, v := range vars {
+			t.Setenv(v, "")
+		}
+	}
+}
+
+func TestEnvTokenSource(t *testing.T) {
+	clearTokenEnv(t)
+	t.Setenv("GITHUB_TOKEN", "generic_github_token")
+	t.Setenv("GH2JIRA_GITHUB_TOKEN", "specific_github_token")
+	t.Setenv("JIRA_TOKEN", " generic_jira_token\n")
+
+	tokens, err := TokenChain{EnvTokenSource{}}.Tokens()
+	require.NoError(t, err)
+	require.Equal(t, &TokenPair{GithubToken: "specific_github_token", JiraToken: "generic_jira_token"}, tokens)
+}
+
+func TestHelperTokenSource(t *testing.T) {
+	defer func(orig func(string, ...string) ([]byte, error)) { runHelper = orig }(runHelper)
+
+	tests := []struct {
+		name    string
+		command string
+		helper  func(name string, args ...string) ([]byte, error)
+		audit   func(t *testing.T, tokens *TokenPair, err error)
+	}{
+		{
+			name:    "passes the kind of token as the last argument",
+			command: "vault-helper --team dev",
+			helper: func(name string, args ...string) ([]byte, error) {
+				return []byte(fmt.Sprintf("%s %v\n", name, args)), nil
+			},
+			audit: func(t *testing.T, tokens *TokenPair, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "vault-helper [--team dev github]", tokens.GithubToken)
+				require.Equal(t, "vault-helper [--team dev jira]", tokens.JiraToken)
+			},
+		},
+		{
+			name:    "helper without a token",
+			command: "vault-helper",
+			helper: func(name string, args ...string) ([]byte, error) {
+				return nil, nil
+			},
+			audit: func(t *testing.T, tokens *TokenPair, err error) {
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{}, tokens)
+			},
+		},
+		{
+			name:    "failing helper",
+			command: "vault-helper",
+			helper: func(name string, args ...string) ([]byte, error) {
+				return nil, errors.New("exit status 1")
+			},
+			audit: func(t *testing.T, tokens *TokenPair, err error) {
+				require.EqualError(t, err, `credential helper "vault-helper" failed to provide the github token: exit status 1`)
+			},
+		},
+		{
+			name:    "empty command",
+			command: " ",
+			audit: func(t *testing.T, tokens *TokenPair, err error) {
+				require.EqualError(t, err, "empty credential helper command")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runHelper = tt.helper
+			tokens, err := TokenChain{&HelperTokenSource{Command: tt.command}}.Tokens()
+			tt.audit(t, tokens, err)
+		})
+	}
+}
+
+func TestFileTokenSource(t *testing.T) {
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+
+	reads := 0
+	readTokens = func(filename string) (*TokenPair, error) {
+		reads++
+		return nil, fmt.Errorf("open %s: %w", filename, fs.ErrNotExist)
+	}
+
+	_, err := TokenChain{&FileTokenSource{File: "missing.yaml"}}.Tokens()
+	require.EqualError(t, err, "open missing.yaml: file does not exist")
+
+	reads = 0
+	tokens, err := TokenChain{&FileTokenSource{File: "missing.yaml", Optional: true}}.Tokens()
+	require.NoError(t, err)
+	require.Equal(t, &TokenPair{}, tokens)
+	require.Equal(t, 1, reads)
+
+	readTokens = func(filename string) (*TokenPair, error) {
+		return nil, errors.New("mock tokens read error")
+	}
+	_, err = TokenChain{&FileTokenSource{File: "broken.yaml", Optional: true}}.Tokens()
+	require.EqualError(t, err, "mock tokens read error")
+}
+
+func TestConfig_ReadTokenSources(t *testing.T) {
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+	defer func(orig func(string, ...string) ([]byte, error)) { runHelper = orig }(runHelper)
+	defer func(orig func(string) ([]byte, error)) { readProfiles = orig }(readProfiles)
+	readProfiles = mockReadProfilesSuccess
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		flags  *util.FlagFeeder
+		helper func(name string, args ...string) ([]byte, error)
+		files  map[string]*TokenPair
+		audit  func(t *testing.T, err error, c *Config)
+	}{
+		{
+			name:  "environment takes precedence over the token file",
+			env:   map[string]string{"GITHUB_TOKEN": "env_github_token"},
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml"},
+			files: map[string]*TokenPair{"tokens.yaml": {GithubToken: "file_github_token", JiraToken: "file_jira_token"}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "env_github_token", JiraToken: "file_jira_token"}, c.Tokens)
+			},
+		},
+		{
+			name:  "credential helper takes precedence over the token file",
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml", CredentialHelper: "helper"},
+			helper: func(name string, args ...string) ([]byte, error) {
+				if args[0] == "jira" {
+					return []byte("helper_jira_token"), nil
+				}
+				return nil, nil
+			},
+			files: map[string]*TokenPair{"tokens.yaml": {GithubToken: "file_github_token", JiraToken: "file_jira_token"}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "file_github_token", JiraToken: "helper_jira_token"}, c.Tokens)
+			},
+		},
+		{
+			name:  "profile token store and credential helper",
+			flags: &util.FlagFeeder{ProfilesFile: "profiles.yaml", ProfileName: "Test Profile", CredentialHelper: "helper"},
+			helper: func(name string, args ...string) ([]byte, error) {
+				return nil, nil
+			},
+			files: map[string]*TokenPair{"valid_token_file.yaml": {GithubToken: "profile_github_token"}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.NoError(t, c.RequireGithubToken())
+				require.Equal(t, "profile_github_token", c.Tokens.GithubToken)
+				require.EqualError(t, c.RequireJiraToken(), `missing required jira token (looked in: environment (GH2JIRA_GITHUB_TOKEN, GITHUB_TOKEN, GH2JIRA_JIRA_TOKEN, JIRA_TOKEN), credential helper "helper", token file "valid_token_file.yaml")`)
+			},
+		},
+		{
+			name:  "github token only from the environment without the default token file",
+			env:   map[string]string{"GH2JIRA_GITHUB_TOKEN": "env_github_token"},
+			flags: &util.FlagFeeder{},
+			files: map[string]*TokenPair{},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.NoError(t, c.RequireGithubToken())
+				require.EqualError(t, c.RequireJiraToken(), `missing required jira token (looked in: environment (GH2JIRA_GITHUB_TOKEN, GITHUB_TOKEN, GH2JIRA_JIRA_TOKEN, JIRA_TOKEN), token file "tokenstore.yaml")`)
+			},
+		},
+		{
+			name:  "jira auth settings from the token file when the environment has every token",
+			env:   map[string]string{"GH2JIRA_GITHUB_TOKEN": "env_github_token", "GH2JIRA_JIRA_TOKEN": "env_jira_token"},
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml"},
+			files: map[string]*TokenPair{"tokens.yaml": {JiraAuth: &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"}}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{
+					GithubToken: "env_github_token",
+					JiraToken:   "env_jira_token",
+					JiraAuth:    &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"},
+				}, c.Tokens)
+			},
+		},
+		{
+			name:  "github token from the environment takes precedence over the github app of the token file",
+			env:   map[string]string{"GITHUB_TOKEN": "env_github_token"},
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml"},
+			files: map[string]*TokenPair{"tokens.yaml": {JiraToken: "file_jira_token", GithubApp: &GithubApp{AppID: 1, InstallationID: 2, PrivateKeyFile: "app.pem"}}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.Equal(t, &TokenPair{GithubToken: "env_github_token", JiraToken: "file_jira_token"}, c.Tokens)
+			},
+		},
+		{
+			name:  "github app takes precedence over the github token of the same token file",
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml"},
+			files: map[string]*TokenPair{"tokens.yaml": {GithubToken: "file_github_token", GithubApp: &GithubApp{AppID: 1, InstallationID: 2, PrivateKeyFile: "app.pem"}}},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.NoError(t, err)
+				require.Equal(t, &GithubApp{AppID: 1, InstallationID: 2, PrivateKeyFile: "app.pem"}, c.Tokens.GithubApp)
+			},
+		},
+		{
+			name:  "missing token file named by a flag",
+			env:   map[string]string{"GH2JIRA_GITHUB_TOKEN": "env_github_token"},
+			flags: &util.FlagFeeder{TokenFile: "tokens.yaml"},
+			files: map[string]*TokenPair{},
+			audit: func(t *testing.T, err error, c *Config) {
+				require.EqualError(t, err, "open tokens.yaml: file does not exist")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearTokenEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			runHelper = tt.helper
+			readTokens = func(filename string) (*TokenPair, error) {
+				tokens, ok := tt.files[filename]
+				if !ok {
+					return nil, fmt.Errorf("open %s: %w", filename, fs.ErrNotExist)
+				}
+				return tokens, nil
+			}
+			c := NewConfig(tt.flags)
+			err := c.Read()
+			tt.audit(t, err, c)
+		})
+	}
+}
+
+func TestConfig_ReadSingleToken(t *testing.T) {
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+	defer func(orig func(string, ...string) ([]byte, error)) { runHelper = orig }(runHelper)
+	clearTokenEnv(t)
+
+	var looked []string
+	runHelper = func(name string, args ...string) ([]byte, error) {
+		looked = append(looked, args[0])
+		return []byte("helper_" + args[0] + "_token"), nil
+	}
+	readTokens = func(filename string) (*TokenPair, error) {
+		return &TokenPair{JiraAuth: &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"}}, nil
+	}
+
+	c := NewConfig(&util.FlagFeeder{TokenFile: "tokens.yaml", CredentialHelper: "helper"})
+	require.NoError(t, c.ReadSettings())
+	require.NoError(t, c.ReadGithubToken())
+	require.Equal(t, []string{"github"}, looked)
+	require.Equal(t, &TokenPair{GithubToken: "helper_github_token"}, c.Tokens)
+
+	looked = nil
+	require.NoError(t, c.ReadJiraToken())
+	require.Equal(t, []string{"jira"}, looked)
+	require.Equal(t, &TokenPair{
+		GithubToken: "helper_github_token",
+		JiraToken:   "helper_jira_token",
+		JiraAuth:    &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"},
+	}, c.Tokens)
+
+	runHelper = func(name string, args ...string) ([]byte, error) {
+		return nil, nil
+	}
+	c = NewConfig(&util.FlagFeeder{TokenFile: "tokens.yaml", CredentialHelper: "helper"})
+	require.NoError(t, c.ReadSettings())
+	require.EqualError(t, c.ReadGithubToken(), `missing required github token (looked in: environment (GH2JIRA_GITHUB_TOKEN, GITHUB_TOKEN, GH2JIRA_JIRA_TOKEN, JIRA_TOKEN), credential helper "helper", token file "tokens.yaml")`)
+}
@@ -23,8 +23,27 @@ import (
 const schemaName string = "gh2jira.tokenstore"
 
 type TokenPair struct {
-	JiraToken   string `json:"jira"`
-	GithubToken string `json:"github"`
+	JiraToken   string     `json:"jira"`
+	GithubToken string     `json:"github"`
+	JiraAuth    *JiraAuth  `json:"jiraAuth,omitempty"`  // nil for a jira personal access token
+	GithubApp   *GithubApp `json:"githubApp,omitempty"` // nil for a github personal access token
+}
+
+// Remove removes the credentials of a service: its token along with the github app or the jira auth settings
+func (t *TokenPair) Remove(kind TokenKind) {
+	switch kind {
+	case TokenGithub:
+		t.GithubToken = ""
+		t.GithubApp = nil
+	case TokenJira:
+		t.JiraToken = ""
+		t.JiraAuth = nil
+	}
+}
+
+// IsEmpty reports whether no credential remains
+func (t *TokenPair) IsEmpty() bool {
+	return t.GithubToken == "" && t.GithubApp == nil && t.JiraToken == "" && t.JiraAuth == nil
 }
 
 type TokenStore struct {
@@ -32,30 +51,84 @@ type TokenStore struct {
 	Tokens TokenPair `json:"authTokens"`
 }
 
+// DefaultTokenFile is the token store used when neither a flag nor the profile names one; unlike
+// those, it may be missing when the tokens come from other sources
+const DefaultTokenFile string = "tokenstore.yaml"
+
+// ReadTokenStore reads a token store holding both tokens
 func ReadTokenStore(f string) (*TokenStore, error) {
+	c, err := readTokenStore(f)
+	if err != nil {
+		return nil, err
+	}
+	if c.Tokens.GithubToken == "" {
+		return nil, errors.New("missing required github token")
+	}
+	if c.Tokens.JiraToken == "" {
+		return nil, errors.New("missing required jira token")
+	}
+
+	return c, nil
+}
+
+// ReadTokens reads the tokens of a plaintext or encrypted token store, which may hold either token
+func ReadTokens(f string) (*TokenPair, error) {
 	b, err := readFile(f)
 	if err != nil {
 		return nil, err
 	}
+	encrypted, err := isEncrypted(b)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		return openTokenStore(f, b)
+	}
 
+	c, err := parseTokenStore(b)
+	if err != nil {
+		return nil, err
+	}
+	return &c.Tokens, nil
+}
+
+// readTokenStore reads a plaintext token store which may hold either token
+func readTokenStore(f string) (*TokenStore, error) {
+	b, err := readFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseTokenStore(b)
+}
+
+func parseTokenStore(b []byte) (*TokenStore, error) {
 	var c TokenStore
-	err = yaml.Unmarshal(b, &c)
+	err := yaml.Unmarshal(b, &c)
 	if err != nil {
 		return nil, err
 	}
 	if c.Schema != schemaName {
 		return nil, fmt.Errorf("invalid schema: %q should be %q: %v", c.Schema, schemaName, err)
 	}
-	if c.Tokens.GithubToken == "" {
-		return nil, errors.New("missing required github token")
+	if err := c.Tokens.JiraAuth.Validate(); err != nil {
+		return nil, err
 	}
-	if c.Tokens.JiraToken == "" {
-		return nil, errors.New("missing required jira token")
+	if err := c.Tokens.GithubApp.Validate(); err != nil {
+		return nil, err
 	}
 
 	return &c, nil
 }
 
+// writeTokenStore writes tokens to a plaintext token store
+func writeTokenStore(f string, tokens *TokenPair) error {
+	b, err := yaml.Marshal(&TokenStore{Schema: schemaName, Tokens: *tokens})
+	if err != nil {
+		return err
+	}
+	return writeFile(f, b)
+}
+
 // overrideable func for mocking os.ReadFile
 var readFile = func(file string) ([]byte, error) {
 	return os.ReadFile(file)
@@ -113,3 +113,114 @@ func TestReadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenPair_Remove(t *testing.T) {
+	app := &GithubApp{AppID: 1, InstallationID: 2, PrivateKey: "key"}
+	oauth2 := &JiraAuth{Mode: JiraAuthOAuth2, OAuth2: &JiraOAuth2{ClientID: "client", ClientSecret: "secret", CloudID: "cloud", RefreshToken: "refresh"}}
+
+	tests := []struct {
+		name      string
+		tokens    TokenPair
+		kind      TokenKind
+		expected  TokenPair
+		wantEmpty bool
+	}{
+		{
+			name:     "github token",
+			tokens:   TokenPair{GithubToken: "gh", JiraToken: "jira"},
+			kind:     TokenGithub,
+			expected: TokenPair{JiraToken: "jira"},
+		},
+		{
+			name:      "last token",
+			tokens:    TokenPair{JiraToken: "jira"},
+			kind:      TokenJira,
+			wantEmpty: true,
+		},
+		{
+			name:     "github app",
+			tokens:   TokenPair{GithubApp: app, JiraToken: "jira"},
+			kind:     TokenGithub,
+			expected: TokenPair{JiraToken: "jira"},
+		},
+		{
+			name:     "github keeps jira oauth2",
+			tokens:   TokenPair{GithubToken: "gh", JiraAuth: oauth2},
+			kind:     TokenGithub,
+			expected: TokenPair{JiraAuth: oauth2},
+		},
+		{
+			name:     "jira oauth2",
+			tokens:   TokenPair{GithubApp: app, JiraAuth: oauth2},
+			kind:     TokenJira,
+			expected: TokenPair{GithubApp: app},
+		},
+		{
+			name:      "jira basic auth",
+			tokens:    TokenPair{JiraToken: "jira", JiraAuth: &JiraAuth{Mode: JiraAuthBasic, Email: "me@example.com"}},
+			kind:      TokenJira,
+			wantEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tt.tokens
+			tokens.Remove(tt.kind)
+			require.Equal(t, tt.expected, tokens)
+			require.Equal(t, tt.wantEmpty, tokens.IsEmpty())
+		})
+	}
+}
+
+func TestConfig_RemoveTokens(t *testing.T) {
+	defer func(orig func(string) ([]byte, error)) { readFile = orig }(readFile)
+	defer func(orig func(string, []byte) error) { writeFile = orig }(writeFile)
+	defer func(orig func(string) (*TokenPair, error)) { readTokens = orig }(readTokens)
+	defer func(orig func(string) ([]byte, error)) { readSecret = orig }(readSecret)
+	readTokens = ReadTokens
+	readSecret = func(prompt string) ([]byte, error) {
+		return nil, fmt.Errorf("unexpected prompt %q", prompt)
+	}
+
+	tests := []struct {
+		name      string
+		encrypted bool
+	}{
+		{name: "plaintext token store stays plaintext"},
+		{name: "encrypted token store stays encrypted", encrypted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{}
+			readFile = func(file string) ([]byte, error) {
+				return files[file], nil
+			}
+			writeFile = func(file string, data []byte) error {
+				files[file] = data
+				return nil
+			}
+			tokens := &TokenPair{GithubToken: "github_token", JiraToken: "jira_token"}
+			if tt.encrypted {
+				passphrases["tokens.yaml"] = []byte("secret")
+				defer delete(passphrases, "tokens.yaml")
+				require.NoError(t, WriteTokens("tokens.yaml", tokens))
+			} else {
+				require.NoError(t, writeTokenStore("tokens.yaml", tokens))
+			}
+
+			c := &Config{TokenFile: "tokens.yaml"}
+			deleted, err := c.RemoveTokens(TokenGithub)
+			require.NoError(t, err)
+			require.False(t, deleted)
+
+			encrypted, err := IsEncryptedTokenStore("tokens.yaml")
+			require.NoError(t, err)
+			require.Equal(t, tt.encrypted, encrypted)
+			tokens, err = ReadTokens("tokens.yaml")
+			require.NoError(t, err)
+			require.Equal(t, &TokenPair{JiraToken: "jira_token"}, tokens)
+		})
+	}
+}
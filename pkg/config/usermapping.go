@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const userMappingSchemaName string = "gh2jira.usermapping"
+
+// UserMapEntry associates a github login with a jira user, identified
+// by username on jira server/DC or by account id on jira cloud
+type UserMapEntry struct {
+	Github    string `json:"github"`
+	Jira      string `json:"jira,omitempty"`
+	AccountID string `json:"accountId,omitempty"`
+}
+
+type UserMapping struct {
+	Schema string         `json:"schema"`
+	Users  []UserMapEntry `json:"users"`
+}
+
+func ReadUserMapping(f string) (*UserMapping, error) {
+	b, err := readFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var m UserMapping
+	err = yaml.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Schema != userMappingSchemaName {
+		return nil, fmt.Errorf("invalid schema: %q should be %q", m.Schema, userMappingSchemaName)
+	}
+	for i, u := range m.Users {
+		if u.Github == "" {
+			return nil, fmt.Errorf("user mapping entry %d: missing github login", i)
+		}
+		if u.Jira == "" && u.AccountID == "" {
+			return nil, fmt.Errorf("user mapping entry for %q: missing jira username or accountId", u.Github)
+		}
+	}
+
+	return &m, nil
+}
+
+// JiraUser returns the jira user mapped to the github login, or nil if there is none
+func (m *UserMapping) JiraUser(login string) *UserMapEntry {
+	if m == nil || login == "" {
+		return nil
+	}
+	for i := range m.Users {
+		if strings.EqualFold(m.Users[i].Github, login) {
+			return &m.Users[i]
+		}
+	}
+	return nil
+}
+
+// GithubLogin returns the github login mapped to the jira user given by username or account id.
+// Unmapped users are assumed to have the same jira username as github login.
+func (m *UserMapping) GithubLogin(username, accountID string) string {
+	if m != nil {
+		for _, u := range m.Users {
+			if (accountID != "" && u.AccountID == accountID) || (username != "" && strings.EqualFold(u.Jira, username)) {
+				return u.Github
+			}
+		}
+	}
+	return username
+}
@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadUserMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid mapping",
+			data: `
+schema: gh2jira.usermapping
+users:
+- github: jmrodri
+  jira: jesusr
+- github: oceanc80
+  accountId: 5b10ac8d82e05b22cc7d4ef5
+`,
+		},
+		{
+			name:    "invalid schema",
+			data:    "schema: gh2jira.tokenstore\nusers: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing jira user",
+			data:    "schema: gh2jira.usermapping\nusers:\n- github: jmrodri\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readFile = func(file string) ([]byte, error) {
+				return []byte(tt.data), nil
+			}
+			m, err := ReadUserMapping("users.yaml")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, m.Users, 2)
+		})
+	}
+}
+
+func TestUserMapping_Lookups(t *testing.T) {
+	m := &UserMapping{
+		Users: []UserMapEntry{
+			{Github: "jmrodri", Jira: "jesusr"},
+			{Github: "oceanc80", AccountID: "5b10ac8d82e05b22cc7d4ef5"},
+		},
+	}
+
+	require.Equal(t, &m.Users[0], m.JiraUser("JMRodri"))
+	require.Nil(t, m.JiraUser("someoneelse"))
+	require.Equal(t, "jmrodri", m.GithubLogin("jesusr", ""))
+	require.Equal(t, "oceanc80", m.GithubLogin("", "5b10ac8d82e05b22cc7d4ef5"))
+	require.Equal(t, "unmapped", m.GithubLogin("unmapped", "123"))
+
+	var none *UserMapping
+	require.Nil(t, none.JiraUser("jmrodri"))
+	require.Equal(t, "jesusr", none.GithubLogin("jesusr", ""))
+}
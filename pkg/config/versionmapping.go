@@ -0,0 +1,74 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// VersionRule rewrites the titles of github milestones matching the Milestone regular expression
+// into jira version names; Version may refer to submatches, e.g.: "OSDK $1"
+type VersionRule struct {
+	Milestone string `json:"milestone"`
+	Version   string `json:"version"`
+}
+
+// VersionMapping maps github milestones to jira fix versions.  The first rule matching the whole
+// milestone title applies; milestones which no rule matches keep their title as version name,
+// unless Strict is set, in which case they map to no version at all.
+type VersionMapping struct {
+	Rules  []VersionRule `json:"rules,omitempty"`
+	Strict bool          `json:"strict,omitempty"`
+}
+
+// Validate checks that the milestone of each rule is a valid regular expression
+func (m *VersionMapping) Validate() error {
+	if m == nil {
+		return nil
+	}
+	for i, r := range m.Rules {
+		if _, err := compileMilestone(r.Milestone); err != nil {
+			return fmt.Errorf("version mapping rule %d: invalid milestone %q: %v", i, r.Milestone, err)
+		}
+	}
+	return nil
+}
+
+// JiraVersion returns the name of the jira version mapped to a github milestone title, or "" if there is
+// none.  Without a mapping, milestones map to the version of the same name.
+func (m *VersionMapping) JiraVersion(milestone string) (string, error) {
+	if milestone == "" || m == nil {
+		return milestone, nil
+	}
+	for _, r := range m.Rules {
+		re, err := compileMilestone(r.Milestone)
+		if err != nil {
+			return "", err
+		}
+		if match := re.FindStringSubmatchIndex(milestone); match != nil {
+			return string(re.ExpandString(nil, r.Version, milestone, match)), nil
+		}
+	}
+	if m.Strict {
+		return "", nil
+	}
+	return milestone, nil
+}
+
+// compileMilestone compiles a milestone expression which must match the whole title
+func compileMilestone(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
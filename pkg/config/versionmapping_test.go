@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionMapping_JiraVersion(t *testing.T) {
+	mapping := &VersionMapping{
+		Rules: []VersionRule{
+			{Milestone: `v(\d+)\.(\d+)(\.\d+)?`, Version: "OSDK $1.$2"},
+			{Milestone: "Backlog", Version: ""},
+			{Milestone: "next", Version: "OSDK Next"},
+		},
+	}
+	strict := &VersionMapping{Rules: mapping.Rules, Strict: true}
+
+	tests := []struct {
+		name      string
+		mapping   *VersionMapping
+		milestone string
+		version   string
+	}{
+		{name: "rewritten with submatches", mapping: mapping, milestone: "v1.34.2", version: "OSDK 1.34"},
+		{name: "rewritten without optional submatch", mapping: mapping, milestone: "v1.35", version: "OSDK 1.35"},
+		{name: "mapped to no version", mapping: mapping, milestone: "Backlog", version: ""},
+		{name: "matches the whole title", mapping: mapping, milestone: "nextgen", version: "nextgen"},
+		{name: "unmatched keeps its title", mapping: mapping, milestone: "2024 Q3", version: "2024 Q3"},
+		{name: "unmatched strict has no version", mapping: strict, milestone: "2024 Q3", version: ""},
+		{name: "no milestone", mapping: mapping, milestone: "", version: ""},
+		{name: "no mapping keeps the title", mapping: nil, milestone: "v1.34.2", version: "v1.34.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.mapping.Validate())
+			version, err := tt.mapping.JiraVersion(tt.milestone)
+			require.NoError(t, err)
+			require.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestVersionMapping_Validate(t *testing.T) {
+	err := (&VersionMapping{Rules: []VersionRule{{Milestone: "v1.*", Version: "1"}, {Milestone: "v(", Version: "2"}}}).Validate()
+	require.ErrorContains(t, err, `version mapping rule 1: invalid milestone "v("`)
+
+	err = (&VersionMapping{Rules: []VersionRule{{Version: "1"}}}).Validate()
+	require.ErrorContains(t, err, "empty expression")
+}
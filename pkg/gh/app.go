@@ -0,0 +1,153 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"golang.org/x/oauth2"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+// appAuth identifies the installation of a github app
+type appAuth struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+}
+
+// WithAppAuth authenticates as an installation of a github app, with installation tokens which are
+// created from the app's private key and refreshed before they expire
+func WithAppAuth(appID, installationID int64, privateKey []byte) ConnectionOption {
+	return func(c *Connection) error {
+		key, err := parsePrivateKey(privateKey)
+		if err != nil {
+			return err
+		}
+		c.app = &appAuth{appID: appID, installationID: installationID, key: key}
+		return nil
+	}
+}
+
+// WithAuth authenticates as the github app of the tokens if they have one, or else with the github token;
+// the token chain only keeps the app when no source of higher precedence has a github token
+func WithAuth(tokens *config.TokenPair) ConnectionOption {
+	return func(c *Connection) error {
+		app := tokens.GithubApp
+		if app == nil {
+			return WithToken(tokens.GithubToken)(c)
+		}
+		if err := app.Validate(); err != nil {
+			return err
+		}
+		key, err := app.Key()
+		if err != nil {
+			return err
+		}
+		return WithAppAuth(app.AppID, app.InstallationID, key)(c)
+	}
+}
+
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("github app private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse github app private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// jwt returns the RS256 JSON web token authenticating as the app, valid for the next 9 minutes (github
+// accepts up to 10); it is backdated by a minute in case of clock drift
+func (a *appAuth) jwt(now time.Time) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appTransport authenticates requests as the app itself, which may only manage its installations
+type appTransport struct {
+	app  *appAuth
+	base http.RoundTripper
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.app.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationTokenSource creates installation tokens, which expire after an hour
+type installationTokenSource struct {
+	ctx            context.Context
+	client         *github.Client // authenticated as the app
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	t, _, err := s.client.Apps.CreateInstallationToken(s.ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a token for github app installation %d: %v", s.installationID, err)
+	}
+	return &oauth2.Token{AccessToken: t.GetToken(), Expiry: t.GetExpiresAt().Time}, nil
+}
+
+// appTokenSource returns the source of installation tokens, which reuses each token until it expires
+func (c *Connection) appTokenSource() (oauth2.TokenSource, error) {
+	client, err := c.newClient(&http.Client{Transport: &appTransport{app: c.app, base: http.DefaultTransport}})
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(nil, &installationTokenSource{ctx: c.ctx, client: client, installationID: c.app.installationID}), nil
+}
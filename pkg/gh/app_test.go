@@ -0,0 +1,140 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	for _, block := range []*pem.Block{
+		{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		{Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		parsed, err := parsePrivateKey(pem.EncodeToMemory(block))
+		require.NoError(t, err)
+		require.True(t, key.Equal(parsed))
+	}
+
+	_, err = parsePrivateKey([]byte("not a key"))
+	require.EqualError(t, err, "github app private key is not PEM encoded")
+
+	_, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}))
+	require.ErrorContains(t, err, "unable to parse github app private key")
+}
+
+func TestWithAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	c, err := NewConnection(WithAuth(&config.TokenPair{GithubToken: "token"}))
+	require.NoError(t, err)
+	require.Equal(t, "token", c.token)
+	require.Nil(t, c.app)
+
+	c, err = NewConnection(WithAuth(&config.TokenPair{GithubApp: &config.GithubApp{AppID: 1, InstallationID: 2, PrivateKey: keyPEM}}))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), c.app.appID)
+	require.Equal(t, int64(2), c.app.installationID)
+	require.NoError(t, c.Connect())
+
+	_, err = NewConnection(WithAuth(&config.TokenPair{GithubApp: &config.GithubApp{AppID: 1, PrivateKey: keyPEM}}))
+	require.EqualError(t, err, "github app requires an appID and an installationID")
+}
+
+func TestAppAuth_JWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	app := &appAuth{appID: 1234, installationID: 42, key: key}
+
+	now := time.Unix(1700000000, 0)
+	token, err := app.jwt(now)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"alg":"RS256","typ":"JWT"}`, string(header))
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]int64
+	require.NoError(t, json.Unmarshal(b, &claims))
+	require.Equal(t, map[string]int64{"iss": 1234, "iat": now.Unix() - 60, "exp": now.Unix() + 540}, claims)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestInstallationTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	app := &appAuth{appID: 1234, installationID: 42, key: key}
+
+	created := 0
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	mocked := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostAppInstallationsAccessTokensByInstallationId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				created++
+				require.True(t, strings.HasPrefix(r.URL.Path, "/app/installations/42/"))
+				auth := r.Header.Get("Authorization")
+				require.True(t, strings.HasPrefix(auth, "Bearer "))
+				require.Len(t, strings.Split(strings.TrimPrefix(auth, "Bearer "), "."), 3)
+				w.Write(mock.MustMarshal(github.InstallationToken{
+					Token:     github.String("ghs_installation_token"),
+					ExpiresAt: &github.Timestamp{Time: expiry},
+				}))
+			}),
+		),
+	)
+	client := github.NewClient(&http.Client{Transport: &appTransport{app: app, base: mocked.Transport}})
+	ts := oauth2.ReuseTokenSource(nil, &installationTokenSource{ctx: context.Background(), client: client, installationID: 42})
+
+	for i := 0; i < 2; i++ {
+		token, err := ts.Token()
+		require.NoError(t, err)
+		require.Equal(t, "ghs_installation_token", token.AccessToken)
+		require.True(t, expiry.Equal(token.Expiry))
+	}
+	require.Equal(t, 1, created)
+}
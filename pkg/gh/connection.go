@@ -15,7 +15,11 @@ package gh
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v60/github"
 	"golang.org/x/oauth2"
@@ -27,6 +31,9 @@ type Connection struct {
 	transport *http.Client
 	client    *github.Client
 	token     string
+	app       *appAuth // authenticates instead of the token when set
+	baseURL   string   // empty for github.com
+	uploadURL string
 	ctx       context.Context
 }
 
@@ -52,6 +59,27 @@ func WithContext(ctx context.Context) ConnectionOption {
 	}
 }
 
+// WithEnterpriseURLs connects to a github enterprise server instead of github.com; the upload URL
+// defaults to the base URL, and both get the /api/v3/ and /api/uploads/ paths unless they have them.
+// An empty base URL selects github.com.
+func WithEnterpriseURLs(baseURL, uploadURL string) ConnectionOption {
+	return func(c *Connection) error {
+		if baseURL == "" {
+			return nil
+		}
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid github base URL %q", baseURL)
+		}
+		if uploadURL == "" {
+			uploadURL = baseURL
+		}
+		c.baseURL = baseURL
+		c.uploadURL = uploadURL
+		return nil
+	}
+}
+
 func WithClient(client *github.Client) ConnectionOption {
 	return func(c *Connection) error {
 		c.client = client
@@ -60,7 +88,7 @@ func WithClient(client *github.Client) ConnectionOption {
 }
 
 func NewConnection(options ...ConnectionOption) (*Connection, error) {
-	c := &Connection{}
+	c := &Connection{ctx: context.Background()}
 	for _, opt := range options {
 		if err := opt(c); err != nil {
 			return nil, err
@@ -72,21 +100,53 @@ func NewConnection(options ...ConnectionOption) (*Connection, error) {
 
 func (c *Connection) Connect() error {
 	if c.transport == nil {
-		if c.token == "" {
+		var ts oauth2.TokenSource
+		switch {
+		case c.app != nil:
+			var err error
+			ts, err = c.appTokenSource()
+			if err != nil {
+				return err
+			}
+		case c.token == "":
 			return errors.New("cannot create github client without a token")
+		default:
+			ts = oauth2.StaticTokenSource(
+				&oauth2.Token{AccessToken: c.token},
+			)
 		}
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: c.token},
-		)
 		c.transport = oauth2.NewClient(c.ctx, ts)
 		if c.transport == nil {
 			return errors.New("transport is not set")
 		}
 	}
-	c.client = github.NewClient(c.transport)
+	client, err := c.newClient(c.transport)
+	if err != nil {
+		return err
+	}
+	c.client = client
 	if c.client == nil {
 		return errors.New("client is not set")
 	}
 
 	return nil
 }
+
+// ServesHost reports whether the issues linked to on host are those of the server the connection talks to:
+// github.com, or the enterprise server of its base URL
+func (c *Connection) ServesHost(host string) bool {
+	if c.baseURL == "" {
+		return slices.Contains(DefaultLinkHosts, strings.ToLower(host))
+	}
+	u, err := url.Parse(c.baseURL)
+	return err == nil && strings.EqualFold(u.Hostname(), host)
+}
+
+// newClient creates a client of github.com or of the enterprise server
+func (c *Connection) newClient(httpClient *http.Client) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+	if c.baseURL == "" {
+		return client, nil
+	}
+	return client.WithEnterpriseURLs(c.baseURL, c.uploadURL)
+}
@@ -72,3 +72,65 @@ func TestConnection_Connect(t *testing.T) {
 		})
 	}
 }
+
+func TestWithEnterpriseURLs(t *testing.T) {
+	type scenario struct {
+		name      string
+		baseURL   string
+		uploadURL string
+		apiURL    string
+		upURL     string
+		errMatch  string
+	}
+	scenarios := []scenario{
+		{
+			name:   "github.com by default",
+			apiURL: "https://api.github.com/",
+			upURL:  "https://uploads.github.com/",
+		},
+		{
+			name:    "enterprise server",
+			baseURL: "https://github.example.com",
+			apiURL:  "https://github.example.com/api/v3/",
+			upURL:   "https://github.example.com/api/uploads/",
+		},
+		{
+			name:      "enterprise server with upload URL",
+			baseURL:   "https://github.example.com/api/v3/",
+			uploadURL: "https://uploads.github.example.com/",
+			apiURL:    "https://github.example.com/api/v3/",
+			upURL:     "https://uploads.github.example.com/api/uploads/",
+		},
+		{
+			name:     "invalid base URL",
+			baseURL:  "github.example.com",
+			errMatch: `invalid github base URL "github.example.com"`,
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			c, err := NewConnection(WithToken("token"), WithEnterpriseURLs(s.baseURL, s.uploadURL))
+			if s.errMatch != "" {
+				require.EqualError(t, err, s.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, c.Connect())
+			require.Equal(t, s.apiURL, c.client.BaseURL.String())
+			require.Equal(t, s.upURL, c.client.UploadURL.String())
+		})
+	}
+}
+
+func TestConnection_ServesHost(t *testing.T) {
+	c, err := NewConnection(WithToken("token"))
+	require.NoError(t, err)
+	require.True(t, c.ServesHost("github.com"))
+	require.True(t, c.ServesHost("WWW.GitHub.com"))
+	require.False(t, c.ServesHost("github.example.com"))
+
+	c, err = NewConnection(WithToken("token"), WithEnterpriseURLs("https://GitHub.example.com/api/v3/", ""))
+	require.NoError(t, err)
+	require.True(t, c.ServesHost("github.example.com"))
+	require.False(t, c.ServesHost("github.com"))
+}
@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// SetIssueState closes or reopens the issue; a non-empty reason sets the state reason as well
+// (e.g. closing an issue as "not_planned" rather than "completed")
+func (c *Connection) SetIssueState(issueNum int, state string, reason string, options ...ListOption) (*github.Issue, error) {
+	action := &ListSpec{}
+	for _, opt := range options {
+		if err := opt(action); err != nil {
+			return nil, err
+		}
+	}
+	if state != "open" && state != "closed" {
+		return nil, fmt.Errorf("invalid issue state %q (accepted states are 'open', 'closed')", state)
+	}
+
+	req := &github.IssueRequest{
+		State: github.String(state),
+	}
+	switch reason {
+	case "":
+	case "completed", "not_planned", "reopened":
+		req.StateReason = github.String(reason)
+	default:
+		return nil, fmt.Errorf("invalid state reason %q (accepted reasons are 'completed', 'not_planned', 'reopened')", reason)
+	}
+
+	issue, _, err := c.client.Issues.Edit(c.ctx, action.GetGithubOrg(), action.GetGithubRepo(), issueNum, req)
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// AddComment comments on the issue
+func (c *Connection) AddComment(issueNum int, body string, options ...ListOption) error {
+	action := &ListSpec{}
+	for _, opt := range options {
+		if err := opt(action); err != nil {
+			return err
+		}
+	}
+
+	_, _, err := c.client.Issues.CreateComment(c.ctx, action.GetGithubOrg(), action.GetGithubRepo(), issueNum, &github.IssueComment{
+		Body: github.String(body),
+	})
+	return err
+}
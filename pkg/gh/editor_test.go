@@ -0,0 +1,119 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditor_SetIssueState(t *testing.T) {
+	type scenario struct {
+		name     string
+		state    string
+		reason   string
+		wantErr  bool
+		errMatch string
+	}
+	scenarios := []scenario{
+		{
+			name:  "closes issue",
+			state: "closed",
+		},
+		{
+			name:  "reopens issue",
+			state: "open",
+		},
+		{
+			name:   "closes issue as not planned",
+			state:  "closed",
+			reason: "not_planned",
+		},
+		{
+			name:     "rejects invalid state",
+			state:    "merged",
+			wantErr:  true,
+			errMatch: "invalid issue state",
+		},
+		{
+			name:     "rejects invalid state reason",
+			state:    "closed",
+			reason:   "duplicate",
+			wantErr:  true,
+			errMatch: "invalid state reason",
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			c, err := NewConnection(
+				WithToken("token"),
+				WithContext(context.Background()),
+				WithTransport(mock.NewMockedHTTPClient(
+					mock.WithRequestMatchHandler(
+						mock.PatchReposIssuesByOwnerByRepoByIssueNumber,
+						http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							var req github.IssueRequest
+							require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+							w.Write(mock.MustMarshal(github.Issue{
+								Number:      github.Int(123),
+								State:       req.State,
+								StateReason: req.StateReason,
+							}))
+						}),
+					),
+				)),
+			)
+			require.NoError(t, err)
+			require.NoError(t, c.Connect())
+
+			issue, err := c.SetIssueState(123, s.state, s.reason, WithProject("fakeorg/fakeproject"))
+			if s.wantErr {
+				require.ErrorContains(t, err, s.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.state, issue.GetState())
+			require.Equal(t, s.reason, issue.GetStateReason())
+		})
+	}
+}
+
+func TestEditor_AddComment(t *testing.T) {
+	var got string
+	c, err := NewConnection(
+		WithToken("token"),
+		WithContext(context.Background()),
+		WithTransport(mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var comment github.IssueComment
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+					got = comment.GetBody()
+					w.Write(mock.MustMarshal(comment))
+				}),
+			),
+		)),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Connect())
+
+	require.NoError(t, c.AddComment(123, "synced from OSDK-1", WithProject("fakeorg/fakeproject")))
+	require.Equal(t, "synced from OSDK-1", got)
+}
@@ -0,0 +1,147 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LinkKind is the kind of github item a link points to, named after its URL path segment
+type LinkKind string
+
+const (
+	LinkIssue LinkKind = "issues"
+	LinkPull  LinkKind = "pull"
+)
+
+// DefaultLinkHosts are the hosts of the links recognized unless others are configured
+var DefaultLinkHosts = []string{"github.com", "www.github.com"}
+
+// DefaultLinkKinds are the kinds of links recognized unless others are configured
+var DefaultLinkKinds = []LinkKind{LinkIssue, LinkPull}
+
+// IssueRef identifies the github issue or pull request a link points to
+type IssueRef struct {
+	Host    string
+	Project string // owner/repo
+	Number  int
+	Kind    LinkKind
+}
+
+// LinkRecognizer picks the links to github issues and pull requests out of arbitrary links
+type LinkRecognizer struct {
+	hosts []string
+	kinds []LinkKind
+}
+
+type LinkOption func(*LinkRecognizer) error
+
+// WithLinkHosts replaces the recognized hosts, e.g. to add a github enterprise host
+func WithLinkHosts(hosts ...string) LinkOption {
+	return func(r *LinkRecognizer) error {
+		if len(hosts) == 0 {
+			return fmt.Errorf("no link hosts")
+		}
+		r.hosts = nil
+		for _, h := range hosts {
+			r.hosts = append(r.hosts, strings.ToLower(strings.TrimSpace(h)))
+		}
+		return nil
+	}
+}
+
+// WithLinkKinds replaces the recognized kinds of links, e.g. to ignore links to pull requests
+func WithLinkKinds(kinds ...LinkKind) LinkOption {
+	return func(r *LinkRecognizer) error {
+		if len(kinds) == 0 {
+			return fmt.Errorf("no link kinds")
+		}
+		for _, k := range kinds {
+			if !slices.Contains(DefaultLinkKinds, k) {
+				return fmt.Errorf("invalid link kind %q (accepted kinds are %q, %q)", k, LinkIssue, LinkPull)
+			}
+		}
+		r.kinds = kinds
+		return nil
+	}
+}
+
+func NewLinkRecognizer(options ...LinkOption) (*LinkRecognizer, error) {
+	r := &LinkRecognizer{hosts: DefaultLinkHosts, kinds: DefaultLinkKinds}
+	for _, opt := range options {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Recognize returns the issue or pull request a link points to.  Links to other hosts, kinds or
+// pages are ignored with a nil ref, while malformed links to a recognized kind are an error.
+func (r *LinkRecognizer) Recognize(link string) (*IssueRef, error) {
+	ref, kind, err := parseLink(link)
+	if kind == "" || !slices.Contains(r.kinds, kind) {
+		return nil, nil
+	}
+	if !slices.Contains(r.hosts, strings.ToLower(ref.Host)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// ParseLink returns the issue or pull request a link on any host points to
+func ParseLink(link string) (*IssueRef, error) {
+	ref, kind, err := parseLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "" {
+		return nil, fmt.Errorf("not a link to a github issue or pull request: %v", link)
+	}
+	return ref, nil
+}
+
+// parseLink splits a link of the form https://host/owner/repo/(issues|pull)/number[/...]; the kind is
+// returned as soon as the path names one, so that malformed links can be told from unrelated ones
+func parseLink(link string) (*IssueRef, LinkKind, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return nil, "", fmt.Errorf("unable to extract issue attributes from URL: %v", link)
+	}
+	ref := &IssueRef{Host: u.Hostname()}
+
+	s := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(s) < 3 {
+		return ref, "", nil
+	}
+	ref.Kind = LinkKind(s[2])
+	if !slices.Contains(DefaultLinkKinds, ref.Kind) {
+		return ref, "", nil
+	}
+	if len(s) < 4 || s[0] == "" || s[1] == "" {
+		return ref, ref.Kind, fmt.Errorf("unable to extract issue attributes from URL: %v", link)
+	}
+	ref.Project = fmt.Sprintf("%s/%s", s[0], s[1])
+	ref.Number, err = strconv.Atoi(s[3])
+	if err != nil || ref.Number < 1 {
+		return ref, ref.Kind, fmt.Errorf("invalid issue number %q in URL: %v", s[3], link)
+	}
+	return ref, ref.Kind, nil
+}
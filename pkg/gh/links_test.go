@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkRecognizer_Recognize(t *testing.T) {
+	defaults, err := NewLinkRecognizer()
+	require.NoError(t, err)
+	enterprise, err := NewLinkRecognizer(WithLinkHosts("GitHub.Example.com"), WithLinkKinds(LinkIssue))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		recognizer *LinkRecognizer
+		link       string
+		want       *IssueRef
+		wantErr    bool
+	}{
+		{
+			name:       "issue",
+			recognizer: defaults,
+			link:       "https://github.com/operator-framework/operator-sdk/issues/6543",
+			want:       &IssueRef{Host: "github.com", Project: "operator-framework/operator-sdk", Number: 6543, Kind: LinkIssue},
+		},
+		{
+			name:       "pull request with trailing page and fragment",
+			recognizer: defaults,
+			link:       "https://www.github.com/operator-framework/operator-sdk/pull/6544/files#diff-1",
+			want:       &IssueRef{Host: "www.github.com", Project: "operator-framework/operator-sdk", Number: 6544, Kind: LinkPull},
+		},
+		{
+			name:       "other page of a repository",
+			recognizer: defaults,
+			link:       "https://github.com/operator-framework/operator-sdk/releases/v1.34.0",
+		},
+		{
+			name:       "other host",
+			recognizer: defaults,
+			link:       "https://github.example.com/org/repo/issues/1",
+		},
+		{
+			name:       "malformed issue number",
+			recognizer: defaults,
+			link:       "https://github.com/org/repo/issues/new",
+			wantErr:    true,
+		},
+		{
+			name:       "not a URL",
+			recognizer: defaults,
+			link:       "see upstream",
+		},
+		{
+			name:       "enterprise host",
+			recognizer: enterprise,
+			link:       "https://github.example.com/org/repo/issues/1",
+			want:       &IssueRef{Host: "github.example.com", Project: "org/repo", Number: 1, Kind: LinkIssue},
+		},
+		{
+			name:       "kind not recognized",
+			recognizer: enterprise,
+			link:       "https://github.example.com/org/repo/pull/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := tt.recognizer.Recognize(tt.link)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ref)
+		})
+	}
+}
+
+func TestNewLinkRecognizer(t *testing.T) {
+	_, err := NewLinkRecognizer(WithLinkKinds("commit"))
+	require.EqualError(t, err, `invalid link kind "commit" (accepted kinds are "issues", "pull")`)
+
+	_, err = NewLinkRecognizer(WithLinkHosts())
+	require.Error(t, err)
+}
+
+func TestParseLink(t *testing.T) {
+	ref, err := ParseLink("https://github.example.com/org/repo/pull/7")
+	require.NoError(t, err)
+	require.Equal(t, &IssueRef{Host: "github.example.com", Project: "org/repo", Number: 7, Kind: LinkPull}, ref)
+
+	_, err = ParseLink("https://github.com/org/repo")
+	require.Error(t, err)
+}
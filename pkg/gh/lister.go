@@ -14,6 +14,7 @@ package gh
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/google/go-github/v60/github"
@@ -24,6 +25,7 @@ type ListSpec struct {
 	milestone string
 	assignee  string
 	labels    []string
+	state     string
 }
 
 type ListOption func(*ListSpec) error
@@ -56,6 +58,19 @@ func WithLabels(labels ...string) ListOption {
 	}
 }
 
+// WithState filters listed issues by state: open, closed, or all (default: open)
+func WithState(state string) ListOption {
+	return func(l *ListSpec) error {
+		switch state {
+		case "", "open", "closed", "all":
+			l.state = state
+			return nil
+		default:
+			return fmt.Errorf("invalid issue state %q (accepted states are 'open', 'closed', 'all')", state)
+		}
+	}
+}
+
 func (a *ListSpec) GetGithubOrg() string {
 	return strings.Split(a.project, "/")[0]
 }
@@ -69,6 +84,11 @@ func (a *ListSpec) GetGithubRepo() string {
 }
 
 func (c *Connection) GetIssue(issueNum int, options ...ListOption) (*github.Issue, error) {
+	return c.GetIssueWithContext(c.ctx, issueNum, options...)
+}
+
+// GetIssueWithContext gets the issue using ctx for the request rather than the connection's context
+func (c *Connection) GetIssueWithContext(ctx context.Context, issueNum int, options ...ListOption) (*github.Issue, error) {
 	action := &ListSpec{}
 	for _, opt := range options {
 		if err := opt(action); err != nil {
@@ -76,7 +96,7 @@ func (c *Connection) GetIssue(issueNum int, options ...ListOption) (*github.Issu
 		}
 	}
 
-	issue, _, err := c.client.Issues.Get(c.ctx, action.GetGithubOrg(), action.GetGithubRepo(), issueNum)
+	issue, _, err := c.client.Issues.Get(ctx, action.GetGithubOrg(), action.GetGithubRepo(), issueNum)
 
 	if err != nil {
 		return nil, err
@@ -93,9 +113,14 @@ func (c *Connection) ListIssues(options ...ListOption) ([]*github.Issue, error)
 		}
 	}
 
+	state := action.state
+	if state == "" {
+		state = "open"
+	}
+
 	opt := &github.IssueListByRepoOptions{
 		ListOptions: github.ListOptions{PerPage: 50},
-		State:       "open",
+		State:       state,
 		Milestone:   action.milestone,
 		Assignee:    action.assignee,
 		Labels:      action.labels,
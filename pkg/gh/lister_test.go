@@ -144,6 +144,24 @@ func TestLister_Options(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "WithState sets state",
+			options: []ListOption{
+				WithState("closed"),
+			},
+			want: &ListSpec{
+				state: "closed",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithState rejects unknown state",
+			options: []ListOption{
+				WithState("stale"),
+			},
+			want:    &ListSpec{},
+			wantErr: true,
+		},
 	}
 	for _, s := range scenarios {
 		t.Run(s.name, func(t *testing.T) {
@@ -0,0 +1,158 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"errors"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+// DriftPair is a pair of issues with the time it was first seen mismatched in an unbroken run of snapshots
+type DriftPair struct {
+	reconcile.PairResult
+	DriftingSince time.Time `json:"driftingSince,omitempty"`
+}
+
+// Drifting returns how long the pair has been mismatched as of the given time
+func (p DriftPair) Drifting(asOf time.Time) time.Duration {
+	if p.DriftingSince.IsZero() {
+		return 0
+	}
+	return asOf.Sub(p.DriftingSince)
+}
+
+// Diff compares the mismatches of two snapshots
+type Diff struct {
+	JiraProject string    `json:"jiraProject"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	// NewMismatches are mismatched in the later snapshot, but weren't in the earlier one
+	NewMismatches []DriftPair `json:"newMismatches"`
+	// Fixed were mismatched in the earlier snapshot and match in the later one
+	Fixed []DriftPair `json:"fixed"`
+	// Persistent are mismatched in both snapshots
+	Persistent []DriftPair `json:"persistent"`
+	// Broken were mismatched in the earlier snapshot and their link is broken in the later one, so whether they
+	// still drift is unknown
+	Broken []DriftPair `json:"broken"`
+	// Gone were mismatched in the earlier snapshot and are absent from the later one, e.g. closed in jira
+	Gone []DriftPair `json:"gone"`
+}
+
+func pairKey(p reconcile.PairResult) string {
+	return p.Jira.Name + "|" + p.Git.Name
+}
+
+// Baseline returns the index of the snapshot to compare the latest snapshot with: the latest
+// snapshot taken at least since before the latest one, or the one before the latest when since is 0.
+// When no snapshot is old enough, the oldest is used.
+func Baseline(snaps []*Snapshot, since time.Duration) (int, error) {
+	if len(snaps) < 2 {
+		return 0, errors.New("at least two snapshots are needed for a diff")
+	}
+	latest := len(snaps) - 1
+	if since <= 0 {
+		return latest - 1, nil
+	}
+	cutoff := snaps[latest].Taken.Add(-since)
+	for i := latest - 1; i >= 0; i-- {
+		if !snaps[i].Taken.After(cutoff) {
+			return i, nil
+		}
+	}
+	return 0, nil
+}
+
+// Compare diffs the latest snapshot with the baseline snapshot; the earlier snapshots
+// determine how long the persistent and new mismatches have been drifting
+func Compare(snaps []*Snapshot, baseline int) (*Diff, error) {
+	latest := len(snaps) - 1
+	if baseline < 0 || baseline >= latest {
+		return nil, errors.New("the baseline must be a snapshot taken before the latest")
+	}
+	from, to := snaps[baseline], snaps[latest]
+
+	diff := &Diff{
+		JiraProject:   to.JiraProject,
+		From:          from.Taken,
+		To:            to.Taken,
+		NewMismatches: []DriftPair{},
+		Fixed:         []DriftPair{},
+		Persistent:    []DriftPair{},
+		Broken:        []DriftPair{},
+		Gone:          []DriftPair{},
+	}
+
+	before := make(map[string]bool)
+	for _, p := range from.Results.Mismatches {
+		before[pairKey(p)] = true
+	}
+	now := make(map[string]bool)
+	for _, p := range to.Results.Mismatches {
+		key := pairKey(p)
+		now[key] = true
+		dp := DriftPair{PairResult: p, DriftingSince: driftingSince(snaps, key)}
+		if before[key] {
+			diff.Persistent = append(diff.Persistent, dp)
+		} else {
+			diff.NewMismatches = append(diff.NewMismatches, dp)
+		}
+	}
+
+	matches := make(map[string]reconcile.PairResult)
+	for _, p := range to.Results.Matches {
+		matches[pairKey(p)] = p
+	}
+	broken := make(map[string]reconcile.PairResult)
+	for _, p := range to.Results.Broken {
+		broken[pairKey(p)] = p
+	}
+	for _, p := range from.Results.Mismatches {
+		key := pairKey(p)
+		if now[key] {
+			continue
+		}
+		if m, ok := matches[key]; ok {
+			diff.Fixed = append(diff.Fixed, DriftPair{PairResult: m})
+			continue
+		}
+		if b, ok := broken[key]; ok {
+			diff.Broken = append(diff.Broken, DriftPair{PairResult: b})
+			continue
+		}
+		diff.Gone = append(diff.Gone, DriftPair{PairResult: p})
+	}
+
+	return diff, nil
+}
+
+// driftingSince walks back from the latest snapshot to the first of the unbroken run of snapshots in which the pair is mismatched
+func driftingSince(snaps []*Snapshot, key string) time.Time {
+	var since time.Time
+	for i := len(snaps) - 1; i >= 0; i-- {
+		found := false
+		for _, p := range snaps[i].Results.Mismatches {
+			if pairKey(p) == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+		since = snaps[i].Taken
+	}
+	return since
+}
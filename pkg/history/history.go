@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+const schemaName string = "gh2jira.history"
+
+// snapshot files are named after the time they were taken so that they sort chronologically
+const fileTimeFormat string = "20060102T150405.000000000Z"
+
+// Snapshot is the recorded result of a reconcile run
+type Snapshot struct {
+	Schema        string                 `json:"schema"`
+	Taken         time.Time              `json:"taken"`
+	JiraProject   string                 `json:"jiraProject"`
+	GithubProject string                 `json:"githubProject,omitempty"`
+	Results       *reconcile.TypeResults `json:"results"`
+}
+
+func NewSnapshot(taken time.Time, jiraProject, githubProject string, results *reconcile.TypeResults) *Snapshot {
+	return &Snapshot{
+		Schema:        schemaName,
+		Taken:         taken.UTC(),
+		JiraProject:   jiraProject,
+		GithubProject: githubProject,
+		Results:       results,
+	}
+}
+
+// Store keeps snapshots as JSON files, in a directory per jira project
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, errors.New("no history directory")
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) projectDir(project string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(project, string(filepath.Separator), "_"))
+}
+
+// Save writes the snapshot to the store and returns the path of its file
+func (s *Store) Save(snap *Snapshot) (string, error) {
+	if snap.JiraProject == "" {
+		return "", errors.New("snapshot without a jira project")
+	}
+	dir := s.projectDir(snap.JiraProject)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, snap.Taken.UTC().Format(fileTimeFormat)+".json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Snapshots returns the snapshots of the jira project, oldest first
+func (s *Store) Snapshots(project string) ([]*Snapshot, error) {
+	dir := s.projectDir(project)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []*Snapshot
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(b, &snap); err != nil {
+			return nil, fmt.Errorf("reading snapshot %s: %v", e.Name(), err)
+		}
+		if snap.Schema != schemaName {
+			return nil, fmt.Errorf("reading snapshot %s: invalid schema: %q should be %q", e.Name(), snap.Schema, schemaName)
+		}
+		if snap.Results == nil {
+			snap.Results = &reconcile.TypeResults{}
+		}
+		snaps = append(snaps, &snap)
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Taken.Before(snaps[j].Taken) })
+	return snaps, nil
+}
@@ -0,0 +1,147 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+var day = 24 * time.Hour
+var start = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func pair(jira string, github string) reconcile.PairResult {
+	return reconcile.PairResult{
+		Jira: reconcile.IssueStatus{Name: jira, Status: "In Progress"},
+		Git:  reconcile.IssueStatus{Name: github, Status: "closed"},
+	}
+}
+
+func snapshot(days int, mismatches []reconcile.PairResult, matches []reconcile.PairResult) *Snapshot {
+	return NewSnapshot(start.Add(time.Duration(days)*day), "OPECO", "org/repo", &reconcile.TypeResults{
+		Mismatches: mismatches,
+		Matches:    matches,
+	})
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	snaps, err := store.Snapshots("OPECO")
+	require.NoError(t, err)
+	require.Empty(t, snaps)
+
+	// saved out of order, read back oldest first
+	for _, d := range []int{7, 0, 14} {
+		_, err := store.Save(snapshot(d, []reconcile.PairResult{pair("OPECO-1", "org/repo/1")}, nil))
+		require.NoError(t, err)
+	}
+	other := snapshot(1, nil, nil)
+	other.JiraProject = "OCPBUGS"
+	path, err := store.Save(other)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "OCPBUGS", "20240302T120000.000000000Z.json"), path)
+
+	snaps, err = store.Snapshots("OPECO")
+	require.NoError(t, err)
+	require.Len(t, snaps, 3)
+	require.Equal(t, start, snaps[0].Taken)
+	require.Equal(t, start.Add(7*day), snaps[1].Taken)
+	require.Equal(t, start.Add(14*day), snaps[2].Taken)
+	require.Equal(t, "OPECO-1", snaps[2].Results.Mismatches[0].Jira.Name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "OPECO", "bad.json"), []byte(`{"schema":"gh2jira.tokenstore"}`), 0o644))
+	_, err = store.Snapshots("OPECO")
+	require.EqualError(t, err, `reading snapshot bad.json: invalid schema: "gh2jira.tokenstore" should be "gh2jira.history"`)
+
+	_, err = NewStore("")
+	require.Error(t, err)
+}
+
+func TestBaseline(t *testing.T) {
+	snaps := []*Snapshot{snapshot(0, nil, nil), snapshot(6, nil, nil), snapshot(7, nil, nil), snapshot(14, nil, nil)}
+
+	tests := []struct {
+		name     string
+		since    time.Duration
+		expected int
+	}{
+		{name: "previous run", since: 0, expected: 2},
+		{name: "a week ago", since: 7 * day, expected: 2},
+		{name: "just over a week ago", since: 7*day + time.Hour, expected: 1},
+		{name: "older than all snapshots", since: 30 * day, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := Baseline(snaps, tt.since)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, i)
+		})
+	}
+
+	_, err := Baseline(snaps[:1], 0)
+	require.EqualError(t, err, "at least two snapshots are needed for a diff")
+}
+
+func TestCompare(t *testing.T) {
+	drifting := pair("OPECO-1", "org/repo/1")
+	fixed := pair("OPECO-2", "org/repo/2")
+	gone := pair("OPECO-3", "org/repo/3")
+	added := pair("OPECO-4", "org/repo/4")
+	flapping := pair("OPECO-5", "org/repo/5")
+	broken := pair("OPECO-6", "org/repo/6")
+	broken.Broken = &reconcile.BrokenLink{Reason: reconcile.BrokenNotFound, Message: "github issue not found"}
+
+	snaps := []*Snapshot{
+		snapshot(0, []reconcile.PairResult{drifting, flapping}, nil),
+		snapshot(7, []reconcile.PairResult{drifting, fixed}, []reconcile.PairResult{flapping}),
+		snapshot(14, []reconcile.PairResult{drifting, fixed, gone, flapping, pair("OPECO-6", "org/repo/6")}, nil),
+		snapshot(21, []reconcile.PairResult{drifting, added, flapping}, []reconcile.PairResult{fixed}),
+	}
+	snaps[3].Results.Broken = []reconcile.PairResult{broken}
+
+	diff, err := Compare(snaps, 2)
+	require.NoError(t, err)
+	require.Equal(t, start.Add(14*day), diff.From)
+	require.Equal(t, start.Add(21*day), diff.To)
+
+	require.Equal(t, []DriftPair{
+		{PairResult: drifting, DriftingSince: start},
+		{PairResult: flapping, DriftingSince: start.Add(14 * day)},
+	}, diff.Persistent)
+	require.Equal(t, 21*day, diff.Persistent[0].Drifting(diff.To))
+	require.Equal(t, 7*day, diff.Persistent[1].Drifting(diff.To))
+	require.Equal(t, []DriftPair{{PairResult: added, DriftingSince: start.Add(21 * day)}}, diff.NewMismatches)
+	require.Equal(t, []DriftPair{{PairResult: fixed}}, diff.Fixed)
+	require.Equal(t, []DriftPair{{PairResult: broken}}, diff.Broken)
+	require.Equal(t, []DriftPair{{PairResult: gone}}, diff.Gone)
+
+	// against an older baseline, the flapping pair is persistent while the fixed one was never seen
+	diff, err = Compare(snaps, 0)
+	require.NoError(t, err)
+	require.Len(t, diff.Persistent, 2)
+	require.Empty(t, diff.Fixed)
+	require.Empty(t, diff.Broken)
+	require.Empty(t, diff.Gone)
+
+	_, err = Compare(snaps, 3)
+	require.Error(t, err)
+}
@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+// AtlassianEndpoint is the OAuth 2.0 (3LO) endpoint of Jira Cloud
+var AtlassianEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://auth.atlassian.com/authorize",
+	TokenURL:  "https://auth.atlassian.com/oauth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// WithAuth authenticates the way the jira auth settings of the tokens select: with the jira token as a
+// personal access token by default, as the API token of a Jira Cloud account, or with OAuth 2.0 (3LO)
+// tokens.  Refreshed OAuth 2.0 tokens are passed to saveAuth, if set, so that they outlive the run.
+func WithAuth(tokens *config.TokenPair, saveAuth func(*config.JiraAuth) error) ConnectionOption {
+	return func(c *Connection) error {
+		auth := tokens.JiraAuth
+		if err := auth.Validate(); err != nil {
+			return err
+		}
+		switch auth.GetMode() {
+		case config.JiraAuthBasic:
+			return WithBasicAuth(auth.Email, tokens.JiraToken)(c)
+		case config.JiraAuthOAuth2:
+			return WithOAuth2(newOAuth2TokenSource(c, auth, saveAuth), auth.OAuth2.CloudID)(c)
+		}
+		return WithAuthToken(tokens.JiraToken)(c)
+	}
+}
+
+func newOAuth2TokenSource(c *Connection, auth *config.JiraAuth, saveAuth func(*config.JiraAuth) error) oauth2.TokenSource {
+	conf := &oauth2.Config{
+		ClientID:     auth.OAuth2.ClientID,
+		ClientSecret: auth.OAuth2.ClientSecret,
+		Endpoint:     AtlassianEndpoint,
+	}
+	token := &oauth2.Token{
+		AccessToken:  auth.OAuth2.AccessToken,
+		RefreshToken: auth.OAuth2.RefreshToken,
+		Expiry:       auth.OAuth2.Expiry,
+	}
+	return &savingTokenSource{
+		src:  conf.TokenSource(c.ctx, token),
+		auth: auth,
+		last: token.AccessToken,
+		save: saveAuth,
+	}
+}
+
+// savingTokenSource saves the tokens whenever they are refreshed: Atlassian rotates refresh tokens,
+// so the one in the token store stops working once it has been used
+type savingTokenSource struct {
+	src  oauth2.TokenSource
+	auth *config.JiraAuth
+	last string
+	save func(*config.JiraAuth) error
+
+	mu sync.Mutex
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.src.Token()
+	if err != nil {
+		return nil, fmt.Errorf("unable to refresh the jira oauth2 tokens: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.AccessToken == s.last {
+		return t, nil
+	}
+
+	o := *s.auth.OAuth2
+	o.AccessToken = t.AccessToken
+	o.RefreshToken = t.RefreshToken
+	o.Expiry = t.Expiry
+	auth := *s.auth
+	auth.OAuth2 = &o
+	if s.save != nil {
+		// the tokens count as saved only once they are, so that a failed save is retried
+		if err := s.save(&auth); err != nil {
+			return nil, fmt.Errorf("unable to save the refreshed jira oauth2 tokens: %v", err)
+		}
+	}
+	s.auth = &auth
+	s.last = t.AccessToken
+	return t, nil
+}
@@ -0,0 +1,161 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+func TestWithAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   *config.TokenPair
+		expected string
+		errMatch string
+	}{
+		{
+			name:     "personal access token by default",
+			tokens:   &config.TokenPair{JiraToken: "pat"},
+			expected: "Bearer pat",
+		},
+		{
+			name:     "bearer mode",
+			tokens:   &config.TokenPair{JiraToken: "pat", JiraAuth: &config.JiraAuth{Mode: config.JiraAuthBearer}},
+			expected: "Bearer pat",
+		},
+		{
+			name:     "basic mode",
+			tokens:   &config.TokenPair{JiraToken: "api-token", JiraAuth: &config.JiraAuth{Mode: config.JiraAuthBasic, Email: "me@example.com"}},
+			expected: "Basic " + base64.StdEncoding.EncodeToString([]byte("me@example.com:api-token")),
+		},
+		{
+			name:     "basic mode without email",
+			tokens:   &config.TokenPair{JiraToken: "api-token", JiraAuth: &config.JiraAuth{Mode: config.JiraAuthBasic}},
+			errMatch: `jira auth mode "basic" requires an email`,
+		},
+		{
+			name:     "missing token",
+			tokens:   &config.TokenPair{JiraAuth: &config.JiraAuth{Mode: config.JiraAuthBasic, Email: "me@example.com"}},
+			errMatch: "cannot access jira without a token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				w.Write([]byte("{}"))
+			}))
+			defer srv.Close()
+
+			c, err := NewConnection(WithBaseURI(srv.URL), WithAuth(tt.tokens, nil))
+			if tt.errMatch != "" {
+				require.EqualError(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, c.Connect())
+			req, err := c.Client.NewRequest("GET", "rest/api/2/myself", nil)
+			require.NoError(t, err)
+			_, err = c.Client.Do(req, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, authorization)
+		})
+	}
+}
+
+func TestWithAuth_OAuth2(t *testing.T) {
+	defer func(orig string) { AtlassianEndpoint.TokenURL = orig }(AtlassianEndpoint.TokenURL)
+
+	refreshes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		require.Equal(t, "old-refresh", r.Form.Get("refresh_token"))
+		require.Equal(t, "client", r.Form.Get("client_id"))
+		require.Equal(t, "secret", r.Form.Get("client_secret"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer srv.Close()
+	AtlassianEndpoint.TokenURL = srv.URL
+
+	auth := &config.JiraAuth{
+		Mode: config.JiraAuthOAuth2,
+		OAuth2: &config.JiraOAuth2{
+			ClientID:     "client",
+			ClientSecret: "secret",
+			CloudID:      "cloud-id",
+			AccessToken:  "expired-access",
+			RefreshToken: "old-refresh",
+			Expiry:       time.Now().Add(-time.Hour),
+		},
+	}
+	var saved []*config.JiraAuth
+	save := func(a *config.JiraAuth) error {
+		saved = append(saved, a)
+		return nil
+	}
+
+	c, err := NewConnection(WithBaseURI("https://example.atlassian.net"), WithAuth(&config.TokenPair{JiraAuth: auth}, save))
+	require.NoError(t, err)
+	require.Equal(t, "https://api.atlassian.com/ex/jira/cloud-id", c.apiUri())
+	require.Equal(t, "https://example.atlassian.net/browse/OPECO-1", c.BrowseURL("OPECO-1"))
+
+	for i := 0; i < 2; i++ {
+		token, err := c.oauth2.Token()
+		require.NoError(t, err)
+		require.Equal(t, "new-access", token.AccessToken)
+	}
+	require.Equal(t, 1, refreshes)
+	require.Len(t, saved, 1)
+	require.Equal(t, "new-access", saved[0].OAuth2.AccessToken)
+	require.Equal(t, "new-refresh", saved[0].OAuth2.RefreshToken)
+	require.Equal(t, "cloud-id", saved[0].OAuth2.CloudID)
+	require.True(t, saved[0].OAuth2.Expiry.After(time.Now()))
+	require.Equal(t, "old-refresh", auth.OAuth2.RefreshToken)
+
+	var saveErr error
+	saved = nil
+	c, err = NewConnection(WithBaseURI("https://example.atlassian.net"), WithAuth(&config.TokenPair{JiraAuth: auth}, func(a *config.JiraAuth) error {
+		if saveErr != nil {
+			return saveErr
+		}
+		saved = append(saved, a)
+		return nil
+	}))
+	require.NoError(t, err)
+	saveErr = errors.New("read-only token store")
+	_, err = c.oauth2.Token()
+	require.EqualError(t, err, "unable to save the refreshed jira oauth2 tokens: read-only token store")
+
+	// the refresh token has been rotated, so the save is retried until it succeeds
+	saveErr = nil
+	token, err := c.oauth2.Token()
+	require.NoError(t, err)
+	require.Equal(t, "new-access", token.AccessToken)
+	require.Equal(t, 2, refreshes)
+	require.Len(t, saved, 1)
+	require.Equal(t, "new-refresh", saved[0].OAuth2.RefreshToken)
+}
@@ -15,12 +15,12 @@ package jira
 import (
 	"fmt"
 	"io"
-	"path/filepath"
-	"regexp"
 	"strings"
 
 	gojira "github.com/andygrunwald/go-jira"
 	"github.com/google/go-github/v60/github"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
 )
 
 // getDomainFromIssueUrl extracts the github domain from the issue HTML URL
@@ -32,6 +32,9 @@ func getDomainFromIssueUrl(url string) string {
 	}
 
 	parts := strings.Split(url, "/")
+	if len(parts) < 4 {
+		return ""
+	}
 	parts = parts[:len(parts)-2]
 	parts = parts[len(parts)-2:]
 	return strings.Join(parts, "/")
@@ -50,41 +53,90 @@ func getIssueNumberFromIssueUrl(url string) string {
 	return parts[len(parts)-1]
 }
 
-// expandDescription expands checklist links to other issues to jira link format
-// assumes the input checklist lines have the format "- [ ] #123"
-// replaces those lines in the general format " * [domain/project/issues/123|url]"
-// makes a special effort to preserve any trailing text after the issue number
-func expandDescription(body, url string) (string, error) {
-	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
-	var out []string
-
-	matcher := regexp.MustCompile(`^- \[ \] #[0-9]+`)
-
-	for _, line := range lines {
-		if matcher.FindStringIndex(line) != nil {
-			// extract the linked issue number
-			parts := strings.Split(line, "#")
-			elements := strings.Split(parts[1], " ")
-			issue := elements[0]
-			// grab any trailing text after the issue number
-			var trailer string
-			if len(elements) > 1 {
-				trailer = strings.Join(elements[1:], " ")
-			}
-			out = append(out,
-				fmt.Sprintf(" * [%s|%s] %s",
-					fmt.Sprintf("%s#%s", getDomainFromIssueUrl(url), issue),
-					strings.Replace(url, getIssueNumberFromIssueUrl(url), issue, -1),
-					trailer))
-		} else {
-			out = append(out, line)
+// ExistingAction controls what Clone does when the github issue already has a jira clone
+type ExistingAction string
+
+const (
+	// ExistingSkip reports the existing clone and leaves it untouched
+	ExistingSkip ExistingAction = "skip"
+	// ExistingUpdate refreshes the summary and description of the existing clone, along with its fix version,
+	// labels, components and assignee when the mappings set them; the issue type and reporter are left as they are
+	ExistingUpdate ExistingAction = "update"
+)
+
+type CloneSpec struct {
+	clones     CloneIndex
+	onExisting ExistingAction
+	mapping    *config.CloneMapping
+	users      *config.UserMapping
+	versions   *config.VersionMapping
+}
+
+type CloneOption func(*CloneSpec) error
+
+// WithExistingClones supplies an index of github issue URL -> jira key, as returned by FindClones,
+// which Clone consults before creating a new issue.  Issues created by Clone are added to the index.
+func WithExistingClones(clones CloneIndex) CloneOption {
+	return func(s *CloneSpec) error {
+		s.clones = clones
+		return nil
+	}
+}
+
+func WithOnExisting(action ExistingAction) CloneOption {
+	return func(s *CloneSpec) error {
+		a, err := ParseExistingAction(string(action))
+		if err != nil {
+			return err
 		}
+		s.onExisting = a
+		return nil
+	}
+}
+
+// ParseExistingAction returns the action for existing clones with the given name
+func ParseExistingAction(name string) (ExistingAction, error) {
+	switch a := ExistingAction(name); a {
+	case ExistingSkip, ExistingUpdate:
+		return a, nil
+	default:
+		return "", fmt.Errorf("invalid action for existing clones %q (accepted actions are %q, %q)", name, ExistingSkip, ExistingUpdate)
+	}
+}
+
+// WithFieldMapping sets the issue type, summary and description templates, and label rules of the clone
+func WithFieldMapping(mapping *config.CloneMapping) CloneOption {
+	return func(s *CloneSpec) error {
+		s.mapping = mapping
+		return nil
+	}
+}
+
+// WithUserMapping sets the assignee and reporter of the clone to the jira users mapped
+// to the github assignee and author; unmapped users are left for jira to default
+func WithUserMapping(users *config.UserMapping) CloneOption {
+	return func(s *CloneSpec) error {
+		s.users = users
+		return nil
 	}
+}
 
-	return strings.Join(out, "\n"), nil
+// WithVersionMapping sets the fix version of the clone to the jira version mapped to the github milestone
+func WithVersionMapping(versions *config.VersionMapping) CloneOption {
+	return func(s *CloneSpec) error {
+		s.versions = versions
+		return nil
+	}
 }
 
-func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bool) (*gojira.Issue, error) {
+func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bool, options ...CloneOption) (*gojira.Issue, error) {
+	spec := &CloneSpec{onExisting: ExistingSkip}
+	for _, opt := range options {
+		if err := opt(spec); err != nil {
+			return nil, err
+		}
+	}
+
 	if conn.Client == nil {
 		// user attempted operation w/o connecting to remote first
 		if err := conn.Connect(); err != nil {
@@ -92,28 +144,27 @@ func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bo
 		}
 	}
 
-	description, err := expandDescription(fromIssue.GetBody(), fromIssue.GetHTMLURL())
+	fm, err := newFieldMapper(spec.mapping, spec.versions)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := fm.fields(fromIssue, project)
 	if err != nil {
 		return nil, err
 	}
 
 	ji := gojira.Issue{
-		Fields: &gojira.IssueFields{
-			// Assignee: &gojira.User{
-			//     Name: "myuser",
-			// },
-			// Reporter: &gojira.User{
-			//     Name: "youruser",
-			// },
-			Description: description,
-			Type: gojira.IssueType{
-				Name: "Story",
-			},
-			Project: gojira.Project{
-				Key: project,
-			},
-			Summary: fmt.Sprintf("[UPSTREAM] %s #%d", fromIssue.GetTitle(), fromIssue.GetNumber()),
-		},
+		Fields: fields,
+	}
+	if u := spec.users.JiraUser(fromIssue.GetAssignee().GetLogin()); u != nil {
+		ji.Fields.Assignee = &gojira.User{Name: u.Jira, AccountID: u.AccountID}
+	}
+	if u := spec.users.JiraUser(fromIssue.GetUser().GetLogin()); u != nil {
+		ji.Fields.Reporter = &gojira.User{Name: u.Jira, AccountID: u.AccountID}
+	}
+
+	if key, ok := spec.clones.Get(fromIssue.GetHTMLURL()); ok {
+		return conn.cloneExisting(key, &ji, fromIssue, spec.onExisting, dryRun)
 	}
 
 	var daIssue *gojira.Issue
@@ -123,6 +174,25 @@ func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bo
 		fmt.Printf("Cloning issue #%d to jira project board: %s\n\n", fromIssue.GetNumber(), ji.Fields.Project.Key)
 		fmt.Printf("Summary: %s\n", ji.Fields.Summary)
 		fmt.Printf("Type: %s\n", ji.Fields.Type.Name)
+		if len(ji.Fields.Labels) > 0 {
+			fmt.Printf("Labels: %s\n", strings.Join(ji.Fields.Labels, ", "))
+		}
+		if len(ji.Fields.Components) > 0 {
+			var names []string
+			for _, c := range ji.Fields.Components {
+				names = append(names, c.Name)
+			}
+			fmt.Printf("Components: %s\n", strings.Join(names, ", "))
+		}
+		if len(ji.Fields.FixVersions) > 0 {
+			fmt.Printf("Fix version: %s\n", ji.Fields.FixVersions[0].Name)
+		}
+		if ji.Fields.Assignee != nil {
+			fmt.Printf("Assignee: %s\n", jiraUserName(ji.Fields.Assignee))
+		}
+		if ji.Fields.Reporter != nil {
+			fmt.Printf("Reporter: %s\n", jiraUserName(ji.Fields.Reporter))
+		}
 		fmt.Println("Description:")
 		fmt.Printf("%s\n", ji.Fields.Description)
 		fmt.Printf("Domain: %s\n", getDomainFromIssueUrl(fromIssue.GetHTMLURL()))
@@ -131,21 +201,22 @@ func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bo
 		fmt.Println("\n############# DRY RUN MODE #############")
 	} else {
 		fmt.Printf("Cloning issue #%d to jira project board: %s\n\n", fromIssue.GetNumber(), ji.Fields.Project.Key)
-		var err error
 
-		daIssue, response, err := conn.Client.Issue.Create(&ji)
+		var response *gojira.Response
+		daIssue, response, err = conn.Client.Issue.Create(&ji)
 		if err != nil {
 			fmt.Printf("Error cloning issue: %v\n", err)
-			reqBody, ioerr := io.ReadAll(response.Response.Body)
-			if ioerr == nil {
-				fmt.Println(string(reqBody))
+			if response != nil {
+				reqBody, ioerr := io.ReadAll(response.Response.Body)
+				if ioerr == nil {
+					fmt.Println(string(reqBody))
+				}
 			}
 			return daIssue, err
 		}
 
 		if daIssue != nil {
-			fmt.Printf("Issue cloned; see %s\n",
-				fmt.Sprintf(filepath.Join(conn.baseUri, "browse/%s"), daIssue.Key))
+			fmt.Printf("Issue cloned; see %s\n", conn.BrowseURL(daIssue.Key))
 		}
 		// Add remote link to the upstream issue
 		if _, _, err = conn.Client.Issue.AddRemoteLink(daIssue.ID, &gojira.RemoteLink{
@@ -156,7 +227,98 @@ func (conn *Connection) Clone(fromIssue *github.Issue, project string, dryRun bo
 		}); err != nil {
 			return nil, err
 		}
+
+		if spec.clones != nil {
+			spec.clones.Add(fromIssue.GetHTMLURL(), daIssue.Key)
+		}
 	}
 
 	return daIssue, nil
 }
+
+// cloneExisting handles a github issue which already has a jira clone according to the requested action
+func (conn *Connection) cloneExisting(key string, ji *gojira.Issue, fromIssue *github.Issue, action ExistingAction, dryRun bool) (*gojira.Issue, error) {
+	existing := &gojira.Issue{Key: key}
+
+	if action != ExistingUpdate {
+		fmt.Printf("Issue #%d already cloned as %s; skipping (see %s)\n", fromIssue.GetNumber(), key, conn.BrowseURL(key))
+		return existing, nil
+	}
+
+	if dryRun {
+		fmt.Println("\n############# DRY RUN MODE #############")
+		fmt.Printf("Updating existing clone %s of issue #%d\n\n", key, fromIssue.GetNumber())
+		fmt.Printf("Summary: %s\n", ji.Fields.Summary)
+		if len(ji.Fields.Labels) > 0 {
+			fmt.Printf("Labels: %s\n", strings.Join(ji.Fields.Labels, ", "))
+		}
+		if len(ji.Fields.Components) > 0 {
+			var names []string
+			for _, c := range ji.Fields.Components {
+				names = append(names, c.Name)
+			}
+			fmt.Printf("Components: %s\n", strings.Join(names, ", "))
+		}
+		if len(ji.Fields.FixVersions) > 0 {
+			fmt.Printf("Fix version: %s\n", ji.Fields.FixVersions[0].Name)
+		}
+		if ji.Fields.Assignee != nil {
+			fmt.Printf("Assignee: %s\n", jiraUserName(ji.Fields.Assignee))
+		}
+		fmt.Println("Description:")
+		fmt.Printf("%s\n", ji.Fields.Description)
+		fmt.Println("\n############# DRY RUN MODE #############")
+		return existing, nil
+	}
+
+	fmt.Printf("Updating existing clone %s of issue #%d\n", key, fromIssue.GetNumber())
+	fields := map[string]interface{}{
+		"summary":     ji.Fields.Summary,
+		"description": ji.Fields.Description,
+	}
+	// labels and components added in jira by hand are kept unless the mapping sets its own
+	if len(ji.Fields.Labels) > 0 {
+		fields["labels"] = ji.Fields.Labels
+	}
+	if len(ji.Fields.Components) > 0 {
+		var components []map[string]string
+		for _, c := range ji.Fields.Components {
+			components = append(components, map[string]string{"name": c.Name})
+		}
+		fields["components"] = components
+	}
+	if len(ji.Fields.FixVersions) > 0 {
+		fields["fixVersions"] = []map[string]string{{"name": ji.Fields.FixVersions[0].Name}}
+	}
+	if u := ji.Fields.Assignee; u != nil {
+		if u.AccountID != "" {
+			fields["assignee"] = map[string]string{"accountId": u.AccountID}
+		} else {
+			fields["assignee"] = map[string]string{"name": u.Name}
+		}
+	}
+	response, err := conn.Client.Issue.UpdateIssue(key, map[string]interface{}{
+		"fields": fields,
+	})
+	if err != nil {
+		fmt.Printf("Error updating issue: %v\n", err)
+		if response != nil {
+			reqBody, ioerr := io.ReadAll(response.Response.Body)
+			if ioerr == nil {
+				fmt.Println(string(reqBody))
+			}
+		}
+		return nil, err
+	}
+	fmt.Printf("Issue updated; see %s\n", conn.BrowseURL(key))
+
+	return existing, nil
+}
+
+// jiraUserName identifies a jira user by username, or by account id on jira cloud
+func jiraUserName(u *gojira.User) string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.AccountID
+}
@@ -0,0 +1,148 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+	"github.com/oceanc80/gh2jira/pkg/jira/mock"
+)
+
+func TestConnection_Clone(t *testing.T) {
+	issue := &github.Issue{
+		Number:   github.Int(42),
+		Title:    github.String("Bundle validation fails"),
+		Body:     github.String("**broken**"),
+		Labels:   []*github.Label{{Name: github.String("kind/bug")}},
+		Assignee: &github.User{Login: github.String("octocat")},
+		HTMLURL:  github.String("https://github.com/operator-framework/operator-sdk/issues/42"),
+	}
+
+	tests := []struct {
+		name       string
+		clones     CloneIndex
+		onExisting ExistingAction
+		options    []CloneOption
+		dryRun     bool
+		key        string
+		requests   []string
+		clonesLeft CloneIndex
+	}{
+		{
+			name:       "new clone",
+			clones:     CloneIndex{},
+			onExisting: ExistingSkip,
+			key:        "OSDK-7",
+			requests: []string{
+				`POST /rest/api/2/issue {"fields":{"description":"*broken*","issuetype":{"name":"Story"},"project":{"key":"OSDK"},"summary":"[UPSTREAM] Bundle validation fails #42"}}`,
+				`POST /rest/api/2/issue/10007/remotelink {"object":{"title":"operator-framework/operator-sdk#42","url":"https://github.com/operator-framework/operator-sdk/issues/42"}}`,
+			},
+			clonesLeft: CloneIndex{"https://github.com/operator-framework/operator-sdk/issues/42": "OSDK-7"},
+		},
+		{
+			name:       "new clone dry run",
+			clones:     CloneIndex{},
+			onExisting: ExistingSkip,
+			dryRun:     true,
+			clonesLeft: CloneIndex{},
+		},
+		{
+			name:       "skip existing clone",
+			clones:     CloneIndex{"https://github.com/operator-framework/operator-sdk/issues/42": "OSDK-1"},
+			onExisting: ExistingSkip,
+			key:        "OSDK-1",
+		},
+		{
+			name:       "update existing clone",
+			clones:     CloneIndex{"https://github.com/operator-framework/operator-sdk/issues/42": "OSDK-1"},
+			onExisting: ExistingUpdate,
+			key:        "OSDK-1",
+			requests: []string{
+				`PUT /rest/api/2/issue/OSDK-1 {"fields":{"description":"*broken*","summary":"[UPSTREAM] Bundle validation fails #42"}}`,
+			},
+		},
+		{
+			name:       "update existing clone with mapped fields",
+			clones:     CloneIndex{"https://github.com/operator-framework/operator-sdk/issues/42": "OSDK-1"},
+			onExisting: ExistingUpdate,
+			options: []CloneOption{
+				WithFieldMapping(&config.CloneMapping{LabelRules: []config.LabelRule{
+					{GithubLabel: "kind/bug", IssueType: "Bug", Labels: []string{"upstream-bug"}, Components: []string{"SDK"}},
+				}}),
+				WithUserMapping(&config.UserMapping{Users: []config.UserMapEntry{{Github: "octocat", Jira: "ocat"}}}),
+			},
+			key: "OSDK-1",
+			requests: []string{
+				`PUT /rest/api/2/issue/OSDK-1 {"fields":{"assignee":{"name":"ocat"},"components":[{"name":"SDK"}],"description":"*broken*","labels":["upstream-bug"],"summary":"[UPSTREAM] Bundle validation fails #42"}}`,
+			},
+		},
+		{
+			name:       "update existing clone dry run",
+			clones:     CloneIndex{"https://github.com/operator-framework/operator-sdk/issues/42": "OSDK-1"},
+			onExisting: ExistingUpdate,
+			dryRun:     true,
+			key:        "OSDK-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			record := func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				b, err := json.Marshal(body)
+				require.NoError(t, err)
+				requests = append(requests, r.Method+" "+r.URL.Path+" "+string(b))
+			}
+			c := newMockedConnection(t,
+				mock.WithRequestMatchHandler(mock.PostIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(w, r)
+					w.WriteHeader(http.StatusCreated)
+					w.Write(mock.MustMarshal(gojira.Issue{ID: "10007", Key: "OSDK-7"}))
+				})),
+				mock.WithRequestMatchHandler(mock.PostIssueRemoteLinkByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(w, r)
+					require.Equal(t, "10007", mux.Vars(r)["issue"])
+					w.WriteHeader(http.StatusCreated)
+					w.Write(mock.MustMarshal(gojira.RemoteLink{ID: 1}))
+				})),
+				mock.WithRequestMatchHandler(mock.PutIssueByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(w, r)
+					w.WriteHeader(http.StatusNoContent)
+				})),
+			)
+
+			options := append([]CloneOption{WithExistingClones(tt.clones), WithOnExisting(tt.onExisting)}, tt.options...)
+			clone, err := c.Clone(issue, "OSDK", tt.dryRun, options...)
+			require.NoError(t, err)
+			if tt.key == "" {
+				require.Nil(t, clone)
+			} else {
+				require.Equal(t, tt.key, clone.Key)
+			}
+			require.Equal(t, tt.requests, requests)
+			if tt.clonesLeft != nil {
+				require.Equal(t, tt.clonesLeft, tt.clones)
+			}
+		})
+	}
+}
@@ -13,20 +13,32 @@
 package jira
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 
 	gojira "github.com/andygrunwald/go-jira"
+	"golang.org/x/oauth2"
 )
 
 type ConnectionOption func(*Connection) error
 
 type Connection struct {
-	transport *gojira.BearerAuthTransport
+	transport *http.Client
 	Client    *gojira.Client
 	token     string
+	email     string             // basic auth with the token as the API token of this Jira Cloud account
+	oauth2    oauth2.TokenSource // OAuth 2.0 (3LO) instead of the token
+	cloudID   string             // the Jira Cloud site reached through the OAuth 2.0 API gateway
 	baseUri   string
+	ctx       context.Context
 }
 
+// jiraCloudAPIGateway is the base URL of the API of Jira Cloud sites for OAuth 2.0 (3LO) apps
+const jiraCloudAPIGateway = "https://api.atlassian.com/ex/jira/"
+
 func WithBaseURI(u string) ConnectionOption {
 	return func(c *Connection) error {
 		c.baseUri = u
@@ -34,6 +46,7 @@ func WithBaseURI(u string) ConnectionOption {
 	}
 }
 
+// WithAuthToken authenticates with a Jira Server or Data Center personal access token
 func WithAuthToken(t string) ConnectionOption {
 	return func(c *Connection) error {
 		c.token = t
@@ -41,22 +54,87 @@ func WithAuthToken(t string) ConnectionOption {
 	}
 }
 
+// WithBasicAuth authenticates with the email and API token of a Jira Cloud account
+func WithBasicAuth(email, apiToken string) ConnectionOption {
+	return func(c *Connection) error {
+		if email == "" {
+			return errors.New("basic auth requires an email")
+		}
+		c.email = email
+		c.token = apiToken
+		return nil
+	}
+}
+
+// WithOAuth2 authenticates with the tokens of a Jira Cloud OAuth 2.0 (3LO) app, which is granted access to the
+// site with the given cloud ID.  Requests go through the Atlassian API gateway, while issues are still browsed
+// at the base URI.
+func WithOAuth2(ts oauth2.TokenSource, cloudID string) ConnectionOption {
+	return func(c *Connection) error {
+		if cloudID == "" {
+			return errors.New("oauth2 requires a cloud ID")
+		}
+		c.oauth2 = ts
+		c.cloudID = cloudID
+		return nil
+	}
+}
+
+func WithContext(ctx context.Context) ConnectionOption {
+	return func(c *Connection) error {
+		c.ctx = ctx
+		return nil
+	}
+}
+
+// for unit testing
+func WithTransport(t *http.Client) ConnectionOption {
+	return func(c *Connection) error {
+		c.transport = t
+		return nil
+	}
+}
+
 func (c *Connection) BaseUri() string { return c.baseUri }
 
+// apiUri returns the base URL of API requests
+func (c *Connection) apiUri() string {
+	if c.cloudID != "" {
+		return jiraCloudAPIGateway + c.cloudID
+	}
+	return c.baseUri
+}
+
+// BrowseURL returns the web URL of the given jira issue
+// (filepath.Join would collapse the "//" of the URL scheme)
+func (c *Connection) BrowseURL(key string) string {
+	return fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(c.baseUri, "/"), key)
+}
+
 func NewConnection(options ...ConnectionOption) (*Connection, error) {
-	c := &Connection{}
+	c := &Connection{ctx: context.Background()}
 	for _, o := range options {
 		if err := o(c); err != nil {
 			return nil, err
 		}
 	}
-	if c.token == "" {
-		return nil, errors.New("cannot access jira without a token")
-	}
 	if c.baseUri == "" {
 		return nil, errors.New("no base URI for jira")
 	}
-	c.transport = &gojira.BearerAuthTransport{Token: c.token}
+	if c.transport != nil {
+		return c, nil
+	}
+
+	switch {
+	case c.oauth2 != nil:
+		c.transport = oauth2.NewClient(c.ctx, c.oauth2)
+	case c.token == "":
+		return nil, errors.New("cannot access jira without a token")
+	case c.email != "":
+		c.transport = (&gojira.BasicAuthTransport{Username: c.email, Password: c.token}).Client()
+	default:
+		c.transport = (&gojira.BearerAuthTransport{Token: c.token}).Client()
+	}
 
 	return c, nil
 }
@@ -66,7 +144,7 @@ func (c *Connection) Connect() error {
 		return errors.New("transport is not set")
 	}
 	if c.Client == nil {
-		gc, err := gojira.NewClient(c.transport.Client(), c.baseUri)
+		gc, err := gojira.NewClient(c.transport, c.apiUri())
 		if err != nil {
 			return err
 		}
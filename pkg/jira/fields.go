@@ -0,0 +1,164 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+const defaultIssueType string = "Story"
+const defaultSummaryTemplate string = "[UPSTREAM] {{ .Title }} #{{ .Number }}"
+const defaultDescriptionTemplate string = "{{ .Body }}"
+
+// CloneData is the data available to the summary and description templates of a clone mapping
+type CloneData struct {
+	Title     string
+	Number    int
+	URL       string
+	Project   string   // github project, e.g.: operator-framework/operator-sdk
+	Body      string   // issue body converted to jira wiki markup
+	RawBody   string   // issue body as written on github
+	Author    string   // github login of the issue author
+	Labels    []string // github label names
+	Milestone string
+	State     string
+}
+
+func newCloneData(issue *github.Issue) *CloneData {
+	d := &CloneData{
+		Title:     issue.GetTitle(),
+		Number:    issue.GetNumber(),
+		URL:       issue.GetHTMLURL(),
+		Project:   getDomainFromIssueUrl(issue.GetHTMLURL()),
+		Body:      convertMarkdown(issue.GetBody(), issue.GetHTMLURL()),
+		RawBody:   issue.GetBody(),
+		Author:    issue.GetUser().GetLogin(),
+		Milestone: issue.GetMilestone().GetTitle(),
+		State:     issue.GetState(),
+	}
+	for _, l := range issue.Labels {
+		d.Labels = append(d.Labels, l.GetName())
+	}
+	return d
+}
+
+// fieldMapper renders the jira fields of a clone according to a clone mapping
+type fieldMapper struct {
+	mapping     config.CloneMapping
+	versions    *config.VersionMapping
+	summary     *template.Template
+	description *template.Template
+}
+
+func newFieldMapper(mapping *config.CloneMapping, versions *config.VersionMapping) (*fieldMapper, error) {
+	fm := &fieldMapper{versions: versions}
+	if mapping != nil {
+		fm.mapping = *mapping
+	}
+	if fm.mapping.IssueType == "" {
+		fm.mapping.IssueType = defaultIssueType
+	}
+	if fm.mapping.Summary == "" {
+		fm.mapping.Summary = defaultSummaryTemplate
+	}
+	if fm.mapping.Description == "" {
+		fm.mapping.Description = defaultDescriptionTemplate
+	}
+
+	var err error
+	fm.summary, err = template.New("summary").Parse(fm.mapping.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary template: %v", err)
+	}
+	fm.description, err = template.New("description").Parse(fm.mapping.Description)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %v", err)
+	}
+
+	return fm, nil
+}
+
+// fields renders the fields of the jira clone of the github issue
+func (fm *fieldMapper) fields(issue *github.Issue, project string) (*gojira.IssueFields, error) {
+	data := newCloneData(issue)
+
+	var summary, description strings.Builder
+	if err := fm.summary.Execute(&summary, data); err != nil {
+		return nil, fmt.Errorf("rendering summary: %v", err)
+	}
+	if err := fm.description.Execute(&description, data); err != nil {
+		return nil, fmt.Errorf("rendering description: %v", err)
+	}
+
+	issueType := fm.mapping.IssueType
+	typeMapped := false
+	labels := slices.Clone(fm.mapping.Labels)
+	components := slices.Clone(fm.mapping.Components)
+	for _, rule := range fm.mapping.LabelRules {
+		if !slices.ContainsFunc(data.Labels, func(l string) bool { return strings.EqualFold(l, rule.GithubLabel) }) {
+			continue
+		}
+		// the first matching rule which names an issue type wins
+		if rule.IssueType != "" && !typeMapped {
+			issueType = rule.IssueType
+			typeMapped = true
+		}
+		labels = appendMissing(labels, rule.Labels...)
+		components = appendMissing(components, rule.Components...)
+	}
+
+	fields := &gojira.IssueFields{
+		Description: description.String(),
+		Type: gojira.IssueType{
+			Name: issueType,
+		},
+		Project: gojira.Project{
+			Key: project,
+		},
+		Summary: strings.TrimSpace(summary.String()),
+		Labels:  labels,
+	}
+	for _, c := range appendMissing(nil, components...) {
+		fields.Components = append(fields.Components, &gojira.Component{Name: c})
+	}
+
+	// without a version mapping the fix version is left alone, since milestones rarely name jira versions as is
+	if fm.versions != nil {
+		version, err := fm.versions.JiraVersion(data.Milestone)
+		if err != nil {
+			return nil, err
+		}
+		if version != "" {
+			fields.FixVersions = []*gojira.FixVersion{{Name: version}}
+		}
+	}
+
+	return fields, nil
+}
+
+func appendMissing(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
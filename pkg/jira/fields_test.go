@@ -0,0 +1,124 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"testing"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/config"
+)
+
+func TestFieldMapper_Fields(t *testing.T) {
+	issue := &github.Issue{
+		Number:  github.Int(42),
+		Title:   github.String("Bundle validation fails"),
+		Body:    github.String("**broken**"),
+		HTMLURL: github.String("https://github.com/operator-framework/operator-sdk/issues/42"),
+		Labels: []*github.Label{
+			{Name: github.String("kind/bug")},
+			{Name: github.String("area/olm")},
+		},
+		Milestone: &github.Milestone{Title: github.String("v1.34.2")},
+	}
+
+	tests := []struct {
+		name     string
+		mapping  *config.CloneMapping
+		versions *config.VersionMapping
+		want     *gojira.IssueFields
+		wantErr  bool
+	}{
+		{
+			name:    "defaults without a mapping",
+			mapping: nil,
+			want: &gojira.IssueFields{
+				Summary:     "[UPSTREAM] Bundle validation fails #42",
+				Description: "*broken*",
+				Type:        gojira.IssueType{Name: "Story"},
+				Project:     gojira.Project{Key: "OSDK"},
+			},
+		},
+		{
+			name: "templates and label rules",
+			mapping: &config.CloneMapping{
+				IssueType:   "Task",
+				Summary:     "{{ .Project }}#{{ .Number }}: {{ .Title }}",
+				Description: "{{ .Body }}\n\nUpstream: {{ .URL }}",
+				Labels:      []string{"upstream"},
+				Components:  []string{"SDK"},
+				LabelRules: []config.LabelRule{
+					{GithubLabel: "kind/feature", IssueType: "Epic"},
+					{GithubLabel: "Kind/Bug", IssueType: "Bug", Labels: []string{"bug", "upstream"}},
+					{GithubLabel: "area/olm", IssueType: "Spike", Components: []string{"OLM", "SDK"}},
+				},
+			},
+			want: &gojira.IssueFields{
+				Summary:     "operator-framework/operator-sdk#42: Bundle validation fails",
+				Description: "*broken*\n\nUpstream: https://github.com/operator-framework/operator-sdk/issues/42",
+				Type:        gojira.IssueType{Name: "Bug"},
+				Project:     gojira.Project{Key: "OSDK"},
+				Labels:      []string{"upstream", "bug"},
+				Components:  []*gojira.Component{{Name: "SDK"}, {Name: "OLM"}},
+			},
+		},
+		{
+			name:     "fix version mapped from milestone",
+			versions: &config.VersionMapping{Rules: []config.VersionRule{{Milestone: `v(\d+\.\d+)\.\d+`, Version: "OSDK $1"}}},
+			want: &gojira.IssueFields{
+				Summary:     "[UPSTREAM] Bundle validation fails #42",
+				Description: "*broken*",
+				Type:        gojira.IssueType{Name: "Story"},
+				Project:     gojira.Project{Key: "OSDK"},
+				FixVersions: []*gojira.FixVersion{{Name: "OSDK 1.34"}},
+			},
+		},
+		{
+			name:     "no fix version for unmapped milestone",
+			versions: &config.VersionMapping{Strict: true},
+			want: &gojira.IssueFields{
+				Summary:     "[UPSTREAM] Bundle validation fails #42",
+				Description: "*broken*",
+				Type:        gojira.IssueType{Name: "Story"},
+				Project:     gojira.Project{Key: "OSDK"},
+			},
+		},
+		{
+			name:    "invalid template",
+			mapping: &config.CloneMapping{Summary: "{{ .Title "},
+			wantErr: true,
+		},
+		{
+			name:    "unknown template field",
+			mapping: &config.CloneMapping{Summary: "{{ .Nope }}"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, err := newFieldMapper(tt.mapping, tt.versions)
+			if err == nil {
+				var fields *gojira.IssueFields
+				fields, err = fm.fields(issue, "OSDK")
+				if err == nil {
+					require.Equal(t, tt.want, fields)
+				}
+			}
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
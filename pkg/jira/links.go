@@ -0,0 +1,115 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gojira "github.com/andygrunwald/go-jira"
+
+	"github.com/oceanc80/gh2jira/pkg/util"
+)
+
+// GetRemoteLinks returns the remote links attached to the given jira issue
+func (c *Connection) GetRemoteLinks(ctx context.Context, key string) ([]gojira.RemoteLink, error) {
+	rlinks, response, err := c.Client.Issue.GetRemoteLinksWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if rlinks == nil {
+		return nil, nil
+	}
+	return *rlinks, nil
+}
+
+// CloneIndex maps the remote link URLs of jira issues to the keys of the issues carrying them
+type CloneIndex map[string]string
+
+// Get returns the key of the jira issue linking to the URL
+func (ci CloneIndex) Get(url string) (string, bool) {
+	key, ok := ci[normalizeLinkURL(url)]
+	return key, ok
+}
+
+// Add records that the jira issue links to the URL; the first issue recorded for a URL is kept
+func (ci CloneIndex) Add(url, key string) {
+	url = normalizeLinkURL(url)
+	if _, ok := ci[url]; !ok {
+		ci[url] = key
+	}
+}
+
+// AddLinks records that the jira issue carries the remote links
+func (ci CloneIndex) AddLinks(rlinks []gojira.RemoteLink, key string) {
+	for _, rlink := range rlinks {
+		if rlink.Object == nil || rlink.Object.URL == "" {
+			continue
+		}
+		ci.Add(rlink.Object.URL, key)
+	}
+}
+
+// FindClones builds an index of remote link URL -> jira issue key for every issue in the project,
+// regardless of status, so that callers can detect github issues which have already been cloned.
+// The remote links of the issues are fetched with at most workers concurrent requests.
+func (c *Connection) FindClones(ctx context.Context, project string, workers int) (CloneIndex, error) {
+	if c.Client == nil {
+		// user attempted operation w/o connecting to remote first
+		if err := c.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	issues, err := c.SearchIssues(fmt.Sprintf("project=%s", project))
+	if err != nil {
+		return nil, err
+	}
+
+	clones := make(CloneIndex)
+	if err := c.AddClones(ctx, clones, issues, workers); err != nil {
+		return nil, err
+	}
+	return clones, nil
+}
+
+// AddClones adds the remote links of the issues to the index, fetching them with at most workers
+// concurrent requests; links are added in the order of the issues, so that the first issue linking to
+// a URL is kept whatever order the requests complete in
+func (c *Connection) AddClones(ctx context.Context, clones CloneIndex, issues []gojira.Issue, workers int) error {
+	links := make([][]gojira.RemoteLink, len(issues))
+	err := util.ForEach(ctx, workers, len(issues), func(ctx context.Context, i int) error {
+		var err error
+		links[i], err = c.GetRemoteLinks(ctx, issues[i].Key)
+		if err != nil {
+			return fmt.Errorf("fetching remote links of %s: %v", issues[i].Key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, rlinks := range links {
+		clones.AddLinks(rlinks, issues[i].Key)
+	}
+	return nil
+}
+
+// normalizeLinkURL makes cosmetic differences between links to the same github issue irrelevant for comparison
+func normalizeLinkURL(url string) string {
+	url = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(url)), "/")
+	return strings.Replace(url, "://www.github.com/", "://github.com/", 1)
+}
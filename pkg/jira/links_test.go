@@ -0,0 +1,133 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/jira/mock"
+)
+
+// newMockedConnection connects to a mocked jira
+func newMockedConnection(t *testing.T, options ...mock.MockBackendOption) *Connection {
+	c, err := NewConnection(WithBaseURI("https://jira.example.com/"), WithTransport(mock.NewMockedHTTPClient(options...)))
+	require.NoError(t, err)
+	require.NoError(t, c.Connect())
+	return c
+}
+
+// searchHandler answers jira searches with the issues
+func searchHandler(t *testing.T, jql string, issues ...gojira.Issue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, jql, r.URL.Query().Get("jql"))
+		w.Write(mock.MustMarshal(map[string]interface{}{"issues": issues, "startAt": 0, "total": len(issues)}))
+	}
+}
+
+func TestConnection_FindClones(t *testing.T) {
+	links := map[string][]string{
+		"OPECO-1": {"https://github.com/org/repo/issues/1", "https://example.com/design-doc"},
+		"OPECO-2": {"https://www.github.com/org/repo/issues/2/"},
+		// a second clone of issue 1 doesn't replace the first one
+		"OPECO-3": {"https://github.com/org/repo/issues/1"},
+		"OPECO-4": nil,
+	}
+	issues := []gojira.Issue{{Key: "OPECO-1"}, {Key: "OPECO-2"}, {Key: "OPECO-3"}, {Key: "OPECO-4"}}
+
+	tests := []struct {
+		name     string
+		workers  int
+		handler  http.HandlerFunc
+		expected CloneIndex
+		errMatch string
+	}{
+		{
+			name:    "single worker",
+			workers: 1,
+			expected: CloneIndex{
+				"https://github.com/org/repo/issues/1": "OPECO-1",
+				"https://github.com/org/repo/issues/2": "OPECO-2",
+				"https://example.com/design-doc":       "OPECO-1",
+			},
+		},
+		{
+			name:    "concurrent lookups keep the first clone",
+			workers: 4,
+			// the links of the first issue arrive last
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if mux.Vars(r)["issue"] == "OPECO-1" {
+					time.Sleep(20 * time.Millisecond)
+				}
+			},
+			expected: CloneIndex{
+				"https://github.com/org/repo/issues/1": "OPECO-1",
+				"https://github.com/org/repo/issues/2": "OPECO-2",
+				"https://example.com/design-doc":       "OPECO-1",
+			},
+		},
+		{
+			name:    "failed lookup",
+			workers: 2,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if mux.Vars(r)["issue"] == "OPECO-2" {
+					mock.WriteError(w, http.StatusInternalServerError, "oh no")
+				}
+			},
+			errMatch: "fetching remote links of OPECO-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, maxInFlight int32
+			c := newMockedConnection(t,
+				mock.WithRequestMatchHandler(mock.GetSearch, searchHandler(t, "project=OPECO", issues...)),
+				mock.WithRequestMatchHandler(mock.GetIssueRemoteLinksByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					n := atomic.AddInt32(&inFlight, 1)
+					defer atomic.AddInt32(&inFlight, -1)
+					for {
+						m := atomic.LoadInt32(&maxInFlight)
+						if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+							break
+						}
+					}
+					if tt.handler != nil {
+						tt.handler(w, r)
+					}
+					var rlinks []gojira.RemoteLink
+					for _, u := range links[mux.Vars(r)["issue"]] {
+						rlinks = append(rlinks, gojira.RemoteLink{Object: &gojira.RemoteLinkObject{URL: u}})
+					}
+					w.Write(mock.MustMarshal(rlinks))
+				})),
+			)
+
+			clones, err := c.FindClones(context.Background(), "OPECO", tt.workers)
+			if tt.errMatch != "" {
+				require.ErrorContains(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, clones)
+			require.LessOrEqual(t, int(maxInFlight), tt.workers)
+		})
+	}
+}
@@ -0,0 +1,364 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlCommentRE  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	fenceRE        = regexp.MustCompile("^(\\s*)(`{3,}|~{3,})\\s*([\\w+#.-]*)")
+	headingRE      = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	ruleRE         = regexp.MustCompile(`^\s{0,3}([-*_])(?:\s*[-*_]){2,}\s*$`)
+	quoteRE        = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	alertRE        = regexp.MustCompile(`^\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\]\s*$`)
+	listRE         = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskRE         = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	tableSepRE     = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	detailsRE      = regexp.MustCompile(`^\s*</?details>\s*$`)
+	summaryRE      = regexp.MustCompile(`^\s*<summary>(.*)</summary>\s*$`)
+	lineBreakRE    = regexp.MustCompile(`<br\s*/?>`)
+	codeSpanRE     = regexp.MustCompile("(`+)(.+?)(`+)")
+	imageRE        = regexp.MustCompile(`!\[([^\]]*)\]\(((?:[^()\s]|\([^()\s]*\))+)(?:\s+"[^"]*")?\)`)
+	linkRE         = regexp.MustCompile(`\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)(?:\s+"[^"]*")?\)`)
+	autolinkRE     = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	bareURLRE      = regexp.MustCompile(`https?://[^\s<>()\[\]|]+`)
+	crossRefRE     = regexp.MustCompile(`(^|[\s(])([\w.-]+/[\w.-]+)#([0-9]+)\b`)
+	issueRefRE     = regexp.MustCompile(`(^|[\s(])#([0-9]+)\b`)
+	boldStarRE     = regexp.MustCompile(`\*\*([^*\n]+?)\*\*`)
+	boldUnderRE    = regexp.MustCompile(`__([^_\n]+?)__`)
+	italicStarRE   = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*\n]*[^*\s])?)\*([^\w*]|$)`)
+	strikeRE       = regexp.MustCompile(`~~([^~\n]+)~~`)
+	placeholderRE  = regexp.MustCompile("\x00([0-9]+)\x00")
+	braceEscapeRE  = regexp.MustCompile(`([{}])`)
+	boldMarker     = "\x01"
+	alertMacros    = map[string]string{"NOTE": "info", "TIP": "tip", "IMPORTANT": "info", "WARNING": "note", "CAUTION": "warning"}
+	listIndentSize = 4 // tab width when measuring list nesting
+)
+
+// codeLanguages maps the languages of fenced code blocks to those the jira code macro can highlight;
+// other languages, e.g. console output, are shown as {noformat} since jira rejects unknown languages
+var codeLanguages = map[string]string{
+	"actionscript": "actionscript", "ada": "ada", "applescript": "applescript",
+	"bash": "bash", "sh": "bash", "shell": "bash", "zsh": "bash",
+	"c": "c", "c#": "c#", "cs": "c#", "csharp": "c#", "c++": "cpp", "cpp": "cpp",
+	"css": "css", "erlang": "erlang", "go": "go", "golang": "go", "groovy": "groovy", "haskell": "haskell",
+	"html": "html", "java": "java", "javascript": "javascript", "js": "javascript", "json": "json", "lua": "lua",
+	"objc": "objc", "objective-c": "objc", "perl": "perl", "php": "php", "python": "python", "py": "python",
+	"r": "r", "ruby": "ruby", "rb": "ruby", "scala": "scala", "sql": "sql", "swift": "swift",
+	"vb": "visualbasic", "visualbasic": "visualbasic", "xml": "xml", "yaml": "yaml", "yml": "yaml",
+}
+
+type listLevel struct {
+	indent int
+	marker string
+}
+
+// markdownConverter converts github flavored markdown to jira wiki markup,
+// resolving bare issue references (#123, owner/repo#123) against the url of the issue being converted
+type markdownConverter struct {
+	issueURL string
+	out      []string
+	lists    []listLevel
+}
+
+// convertMarkdown converts a github issue body to jira wiki markup
+func convertMarkdown(body, issueURL string) string {
+	c := &markdownConverter{issueURL: issueURL}
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = htmlCommentRE.ReplaceAllString(body, "")
+	lines := strings.Split(body, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			c.lists = nil
+			// consecutive blank lines, e.g. around a dropped comment or details tag, separate paragraphs only once
+			if len(c.out) > 0 && c.out[len(c.out)-1] != "" {
+				c.emit("")
+			}
+			continue
+		}
+
+		if m := fenceRE.FindStringSubmatch(line); m != nil {
+			i = c.codeBlock(lines, i, m)
+			continue
+		}
+
+		if i+1 < len(lines) && strings.Contains(line, "|") && strings.Contains(lines[i+1], "|") && tableSepRE.MatchString(lines[i+1]) {
+			i = c.table(lines, i)
+			continue
+		}
+
+		if m := quoteRE.FindStringSubmatch(line); m != nil {
+			i = c.quote(lines, i)
+			continue
+		}
+
+		if m := listRE.FindStringSubmatch(line); m != nil && !ruleRE.MatchString(line) {
+			c.listItem(m)
+			continue
+		}
+		c.lists = nil
+
+		switch {
+		case headingRE.MatchString(line):
+			m := headingRE.FindStringSubmatch(line)
+			c.emit(fmt.Sprintf("h%d. %s", len(m[1]), c.inline(m[2])))
+		case ruleRE.MatchString(line):
+			c.emit("----")
+		case detailsRE.MatchString(line):
+			// collapsible sections have no jira equivalent; their content is always shown
+		case summaryRE.MatchString(line):
+			c.emit("*" + c.inline(summaryRE.FindStringSubmatch(line)[1]) + "*")
+		default:
+			c.emit(c.inline(strings.TrimSpace(line)))
+		}
+	}
+
+	return strings.Trim(strings.Join(c.out, "\n"), "\n ")
+}
+
+func (c *markdownConverter) emit(line string) {
+	c.out = append(c.out, line)
+}
+
+// codeBlock converts a fenced code block starting at lines[start] and returns the index of its closing fence
+func (c *markdownConverter) codeBlock(lines []string, start int, m []string) int {
+	indent, fence, lang := len(m[1]), m[2], codeLanguages[strings.ToLower(m[3])]
+	if lang != "" {
+		c.emit(fmt.Sprintf("{code:%s}", lang))
+	} else {
+		c.emit("{noformat}")
+	}
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			break
+		}
+		// strip the indentation of the opening fence, e.g. for code blocks nested in lists
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		c.emit(line)
+	}
+
+	if lang != "" {
+		c.emit("{code}")
+	} else {
+		c.emit("{noformat}")
+	}
+	c.lists = nil
+	return i
+}
+
+// table converts a table whose header row is lines[start] and returns the index of its last row
+func (c *markdownConverter) table(lines []string, start int) int {
+	c.lists = nil
+	c.emit("||" + strings.Join(c.cells(lines[start]), "||") + "||")
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		c.emit("|" + strings.Join(c.cells(lines[i]), "|") + "|")
+	}
+	return i - 1
+}
+
+func (c *markdownConverter) cells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			// an escaped pipe is part of the cell content, and needs to stay escaped for jira too
+			cell.WriteString(`\|`)
+			i++
+		case row[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	cells = append(cells, cell.String())
+
+	for i := range cells {
+		cells[i] = c.inline(strings.TrimSpace(cells[i]))
+		if cells[i] == "" {
+			// jira collapses empty cells
+			cells[i] = " "
+		}
+	}
+	return cells
+}
+
+// quote converts a run of blockquote lines starting at lines[start] and returns the index of the last one
+func (c *markdownConverter) quote(lines []string, start int) int {
+	c.lists = nil
+	var content []string
+	i := start
+	for ; i < len(lines); i++ {
+		m := quoteRE.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		content = append(content, m[1])
+	}
+
+	macro := "quote"
+	if len(content) > 0 {
+		if m := alertRE.FindStringSubmatch(strings.TrimSpace(content[0])); m != nil {
+			macro = alertMacros[m[1]]
+			content = content[1:]
+		}
+	}
+
+	c.emit("{" + macro + "}")
+	for _, line := range content {
+		c.emit(c.inline(strings.TrimSpace(line)))
+	}
+	c.emit("{" + macro + "}")
+	return i - 1
+}
+
+func (c *markdownConverter) listItem(m []string) {
+	indent := len(strings.ReplaceAll(m[1], "\t", strings.Repeat(" ", listIndentSize)))
+	marker := "*"
+	if m[2][0] >= '0' && m[2][0] <= '9' {
+		marker = "#"
+	}
+
+	for len(c.lists) > 0 && indent < c.lists[len(c.lists)-1].indent {
+		c.lists = c.lists[:len(c.lists)-1]
+	}
+	if len(c.lists) == 0 || indent > c.lists[len(c.lists)-1].indent {
+		c.lists = append(c.lists, listLevel{indent: indent, marker: marker})
+	} else {
+		c.lists[len(c.lists)-1].marker = marker
+	}
+
+	var prefix strings.Builder
+	for _, l := range c.lists {
+		prefix.WriteString(l.marker)
+	}
+
+	text := m[3]
+	if t := taskRE.FindStringSubmatch(text); t != nil {
+		// jira has no checkbox; ( ) reads as an empty one, while (x) would be a red error icon
+		box := "( )"
+		if t[1] != " " {
+			box = "(/)"
+		}
+		text = box + " " + t[2]
+	}
+
+	c.emit(prefix.String() + " " + c.inline(text))
+}
+
+// inline converts the span-level markup of a single line
+func (c *markdownConverter) inline(text string) string {
+	var protected []string
+	protect := func(s string) string {
+		protected = append(protected, s)
+		return fmt.Sprintf("\x00%d\x00", len(protected)-1)
+	}
+
+	// code span content is literal so it's set aside before anything else is touched
+	text = codeSpanRE.ReplaceAllStringFunc(text, func(s string) string {
+		m := codeSpanRE.FindStringSubmatch(s)
+		if m[1] != m[3] {
+			return s
+		}
+		// braces in the code would end the monospace early, or nest another one
+		return protect("{{" + escapeBraces(strings.TrimSpace(m[2])) + "}}")
+	})
+
+	text = lineBreakRE.ReplaceAllString(text, `\\`)
+
+	text = imageRE.ReplaceAllStringFunc(text, func(s string) string {
+		m := imageRE.FindStringSubmatch(s)
+		return protect("!" + m[2] + "!")
+	})
+	text = linkRE.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRE.FindStringSubmatch(s)
+		return protect("[" + c.emphasis(escapeBraces(m[1])) + "|" + m[2] + "]")
+	})
+	text = autolinkRE.ReplaceAllStringFunc(text, func(s string) string {
+		return protect("[" + autolinkRE.FindStringSubmatch(s)[1] + "]")
+	})
+	text = bareURLRE.ReplaceAllStringFunc(text, func(s string) string {
+		url := strings.TrimRight(s, ".,;:!?")
+		return protect(url) + s[len(url):]
+	})
+	text = crossRefRE.ReplaceAllStringFunc(text, func(s string) string {
+		m := crossRefRE.FindStringSubmatch(s)
+		return m[1] + protect(c.issueLink(m[2], m[3]))
+	})
+	text = issueRefRE.ReplaceAllStringFunc(text, func(s string) string {
+		m := issueRefRE.FindStringSubmatch(s)
+		return m[1] + protect(c.issueLink(getDomainFromIssueUrl(c.issueURL), m[2]))
+	})
+
+	// braces in plain text would otherwise be taken for jira macros; urls, set aside above, keep theirs
+	text = c.emphasis(escapeBraces(text))
+
+	return placeholderRE.ReplaceAllStringFunc(text, func(s string) string {
+		var n int
+		fmt.Sscanf(placeholderRE.FindStringSubmatch(s)[1], "%d", &n)
+		return protected[n]
+	})
+}
+
+// escapeBraces escapes the braces which jira would take for macros
+func escapeBraces(text string) string {
+	return braceEscapeRE.ReplaceAllString(text, `\$1`)
+}
+
+// emphasis converts bold, italic and strikethrough markup
+// underscore italics are already valid jira markup and are left alone
+func (c *markdownConverter) emphasis(text string) string {
+	text = boldStarRE.ReplaceAllString(text, boldMarker+"$1"+boldMarker)
+	text = boldUnderRE.ReplaceAllString(text, boldMarker+"$1"+boldMarker)
+	// adjacent matches share their boundary character, so a second pass picks up the ones skipped by the first
+	for n := 0; n < 2; n++ {
+		text = italicStarRE.ReplaceAllString(text, "${1}_${2}_${3}")
+	}
+	text = strikeRE.ReplaceAllString(text, "-$1-")
+	return strings.ReplaceAll(text, boldMarker, "*")
+}
+
+// issueLink renders a reference to issue num of project as a jira link
+// references can only be resolved relative to a known github issue URL, otherwise they are kept as plain text
+func (c *markdownConverter) issueLink(project, num string) string {
+	ref := fmt.Sprintf("%s#%s", project, num)
+	parts := strings.Split(c.issueURL, "/")
+	if c.issueURL == "" || len(parts) < 5 || project == "" {
+		return ref
+	}
+	host := strings.Join(parts[:len(parts)-4], "/")
+	return fmt.Sprintf("[%s|%s/%s/issues/%s]", ref, host, project, num)
+}
@@ -0,0 +1,195 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testIssueURL = "https://github.com/operator-framework/operator-sdk/issues/6000"
+
+func TestConvertMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain text is unchanged",
+			url:      testIssueURL,
+			input:    "The operator fails to reconcile.",
+			expected: "The operator fails to reconcile.",
+		},
+		{
+			name:     "headings",
+			url:      testIssueURL,
+			input:    "# Bug Report\n\n### What did you do? ###",
+			expected: "h1. Bug Report\n\nh3. What did you do?",
+		},
+		{
+			name:     "issue template comments are dropped",
+			url:      testIssueURL,
+			input:    "<!--\nThanks for filing an issue!\nPlease fill in the sections below.\n-->\n## Description\n<!-- what happened -->Crash on start",
+			expected: "h2. Description\nCrash on start",
+		},
+		{
+			name:     "fenced code with and without language",
+			url:      testIssueURL,
+			input:    "```go\nfunc main() {\n\tfmt.Println(\"**not bold**\")\n}\n```\n\n```\n$ operator-sdk version\n```",
+			expected: "{code:go}\nfunc main() {\n\tfmt.Println(\"**not bold**\")\n}\n{code}\n\n{noformat}\n$ operator-sdk version\n{noformat}",
+		},
+		{
+			name:     "inline markup",
+			url:      testIssueURL,
+			input:    "Run **`make test`** with *verbose* and __quiet__ flags, ~~not~~ _now_.",
+			expected: "Run *{{make test}}* with _verbose_ and *quiet* flags, -not- _now_.",
+		},
+		{
+			name:     "inline code content is literal",
+			url:      testIssueURL,
+			input:    "set `spec.replicas: {{ .Values.count }}` to *2*",
+			expected: `set {{spec.replicas: \{\{ .Values.count \}\}}} to _2_`,
+		},
+		{
+			name:     "plain braces are escaped",
+			url:      testIssueURL,
+			input:    "got map{foo:bar}",
+			expected: `got map\{foo:bar\}`,
+		},
+		{
+			name:     "links, autolinks, bare urls and images",
+			url:      testIssueURL,
+			input:    "See [the **docs**](https://sdk.operatorframework.io/docs_v1/) or <https://example.com/a_b_c>, https://example.com/x_y_z.\n![screenshot](https://user-images.githubusercontent.com/1/shot.png)",
+			expected: "See [the *docs*|https://sdk.operatorframework.io/docs_v1/] or [https://example.com/a_b_c], https://example.com/x_y_z.\n!https://user-images.githubusercontent.com/1/shot.png!",
+		},
+		{
+			name:     "code languages jira can't highlight are unformatted",
+			url:      testIssueURL,
+			input:    "```YAML\nkind: Pod\n```\n```golang\npackage main\n```\n```dockerfile\nFROM scratch\n```",
+			expected: "{code:yaml}\nkind: Pod\n{code}\n{code:go}\npackage main\n{code}\n{noformat}\nFROM scratch\n{noformat}",
+		},
+		{
+			name:     "urls with parentheses",
+			url:      testIssueURL,
+			input:    "See [link](https://x.io/a_(b)) and ![img](https://x.io/c_(d).png \"title\").",
+			expected: "See [link|https://x.io/a_(b)] and !https://x.io/c_(d).png!.",
+		},
+		{
+			name:     "issue references",
+			url:      testIssueURL,
+			input:    "Duplicate of #123, related to kubernetes-sigs/kubebuilder#42 (and #7).",
+			expected: "Duplicate of [operator-framework/operator-sdk#123|https://github.com/operator-framework/operator-sdk/issues/123], related to [kubernetes-sigs/kubebuilder#42|https://github.com/kubernetes-sigs/kubebuilder/issues/42] (and [operator-framework/operator-sdk#7|https://github.com/operator-framework/operator-sdk/issues/7]).",
+		},
+		{
+			name:     "issue references without an issue url are left alone",
+			url:      "",
+			input:    "Duplicate of #123",
+			expected: "Duplicate of #123",
+		},
+		{
+			name:     "checklist of linked issues",
+			url:      testIssueURL,
+			input:    "- [ ] #123 docs\r\n- [x] #124\r\n- [ ] write tests",
+			expected: "* ( ) [operator-framework/operator-sdk#123|https://github.com/operator-framework/operator-sdk/issues/123] docs\n* (/) [operator-framework/operator-sdk#124|https://github.com/operator-framework/operator-sdk/issues/124]\n* ( ) write tests",
+		},
+		{
+			name:     "nested and ordered lists",
+			url:      testIssueURL,
+			input:    "1. install OLM\n2. create the bundle\n   - `make bundle`\n   - `make bundle-build`\n     * push it\n3. run it\n\n+ done",
+			expected: "# install OLM\n# create the bundle\n#* {{make bundle}}\n#* {{make bundle-build}}\n#** push it\n# run it\n\n* done",
+		},
+		{
+			name:     "tables",
+			url:      testIssueURL,
+			input:    "| Version | Works? |\n|:--------|:------:|\n| v1.30   | **yes** |\n| v1.31 | no \\| maybe |\n|  | |",
+			expected: "||Version||Works?||\n|v1.30|*yes*|\n|v1.31|no \\| maybe|\n| | |",
+		},
+		{
+			name:     "blockquotes and alerts",
+			url:      testIssueURL,
+			input:    "> quoted *text*\n> more\n\n> [!WARNING]\n> This deletes the CRDs",
+			expected: "{quote}\nquoted _text_\nmore\n{quote}\n\n{note}\nThis deletes the CRDs\n{note}",
+		},
+		{
+			name:     "braces in link urls",
+			url:      testIssueURL,
+			input:    "see [the {dashboard}](https://grafana.example.com/d/{uid}?var={ns}) and {placeholder}",
+			expected: "see [the \\{dashboard\\}|https://grafana.example.com/d/{uid}?var={ns}] and \\{placeholder\\}",
+		},
+		{
+			name:     "blank lines around dropped comments and details",
+			url:      testIssueURL,
+			input:    "before\n\n<!-- hidden -->\n\n<details>\n\n\nafter\n\n</details>\n\nend",
+			expected: "before\n\nafter\n\nend",
+		},
+		{
+			name:     "horizontal rules and details",
+			url:      testIssueURL,
+			input:    "above\n\n---\n\n<details>\n<summary>Logs</summary>\n\nline one<br>line two\n</details>",
+			expected: "above\n\n----\n\n*Logs*\n\nline one\\\\line two",
+		},
+		{
+			name: "realistic bug report",
+			url:  testIssueURL,
+			input: `### Bug Report
+
+<!--
+Note: Make sure to first check the prerequisites that can be found in the main README file!
+-->
+
+#### What did you do?
+
+Ran ` + "`operator-sdk run bundle`" + ` against a **kind** cluster.
+
+#### Environment
+
+* operator-sdk version: v1.33.0
+* Kubernetes version: v1.28
+
+#### Possible Solution
+
+` + "```console" + `
+$ kubectl get csv -A
+` + "```" + `
+
+/kind bug`,
+			expected: `h3. Bug Report
+
+h4. What did you do?
+
+Ran {{operator-sdk run bundle}} against a *kind* cluster.
+
+h4. Environment
+
+* operator-sdk version: v1.33.0
+* Kubernetes version: v1.28
+
+h4. Possible Solution
+
+{noformat}
+$ kubectl get csv -A
+{noformat}
+
+/kind bug`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, convertMarkdown(tt.input, tt.url))
+		})
+	}
+}
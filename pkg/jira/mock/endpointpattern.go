@@ -16,3 +16,58 @@ var PostIssue EndpointPattern = EndpointPattern{
 	Pattern: "/rest/api/2/issue",
 	Method:  "POST",
 }
+
+var GetProjectStatusesByProject EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/project/{project}/statuses",
+	Method:  "GET",
+}
+
+var GetSearch EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/search",
+	Method:  "GET",
+}
+
+var GetIssueRemoteLinksByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}/remotelink",
+	Method:  "GET",
+}
+
+var PostIssueRemoteLinkByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}/remotelink",
+	Method:  "POST",
+}
+
+var PutIssueByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}",
+	Method:  "PUT",
+}
+
+var GetIssueTransitionsByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}/transitions",
+	Method:  "GET",
+}
+
+var PostIssueTransitionsByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}/transitions",
+	Method:  "POST",
+}
+
+var PostIssueCommentByIssue EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/issue/{issue}/comment",
+	Method:  "POST",
+}
+
+var GetProjectByProject EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/project/{project}",
+	Method:  "GET",
+}
+
+var GetWorkflowSchemeProject EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/workflowscheme/project",
+	Method:  "GET",
+}
+
+var GetWorkflowSearch EndpointPattern = EndpointPattern{
+	Pattern: "/rest/api/2/workflow/search",
+	Method:  "GET",
+}
@@ -0,0 +1,184 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	gojira "github.com/andygrunwald/go-jira"
+)
+
+// issueTypeStatuses is an entry of the project statuses API response
+type issueTypeStatuses struct {
+	Name     string          `json:"name"`
+	Statuses []gojira.Status `json:"statuses"`
+}
+
+// GetProjectStatuses returns the statuses of the workflows used by the project's issue types, in the order
+// jira reports them, without duplicates
+func (c *Connection) GetProjectStatuses(ctx context.Context, project string) ([]gojira.Status, error) {
+	if c.Client == nil {
+		// user attempted operation w/o connecting to remote first
+		if err := c.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	var issueTypes []issueTypeStatuses
+	if err := c.get(ctx, fmt.Sprintf("rest/api/2/project/%s/statuses", url.PathEscape(project)), &issueTypes); err != nil {
+		return nil, fmt.Errorf("fetching statuses of project %s: %v", project, err)
+	}
+
+	seen := make(map[string]bool)
+	var statuses []gojira.Status
+	for _, it := range issueTypes {
+		for _, s := range it.Statuses {
+			key := strings.ToLower(s.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses, nil
+}
+
+// StatusCategory returns the name of the status category, or its key when the name is missing
+func StatusCategory(s *gojira.Status) string {
+	if s == nil {
+		return ""
+	}
+	if s.StatusCategory.Name != "" {
+		return s.StatusCategory.Name
+	}
+	return s.StatusCategory.Key
+}
+
+// WorkflowTransition is a transition of a jira workflow between statuses, named rather than identified;
+// a transition without From statuses is global and may be taken from any status
+type WorkflowTransition struct {
+	Name string
+	From []string
+	To   string
+}
+
+// workflowSchemes is the response of the workflow scheme project associations API
+type workflowSchemes struct {
+	Values []struct {
+		WorkflowScheme struct {
+			DefaultWorkflow   string            `json:"defaultWorkflow"`
+			IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+		} `json:"workflowScheme"`
+	} `json:"values"`
+}
+
+// workflowPage is a page of the workflow search API response
+type workflowPage struct {
+	IsLast bool `json:"isLast"`
+	Values []struct {
+		Transitions []struct {
+			Name string   `json:"name"`
+			From []string `json:"from"`
+			To   string   `json:"to"`
+			Type string   `json:"type"`
+		} `json:"transitions"`
+		Statuses []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"statuses"`
+	} `json:"values"`
+}
+
+// GetProjectTransitions returns the transitions of the workflows used by the project's issue types, as found
+// in the project's workflow scheme.  Creating an issue isn't a transition between statuses, so initial
+// transitions are left out.  Reading workflows requires the permission to administer jira.
+func (c *Connection) GetProjectTransitions(ctx context.Context, project string) ([]WorkflowTransition, error) {
+	if c.Client == nil {
+		// user attempted operation w/o connecting to remote first
+		if err := c.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	p, _, err := c.Client.Project.GetWithContext(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("fetching project %s: %v", project, err)
+	}
+
+	var schemes workflowSchemes
+	if err := c.get(ctx, "rest/api/2/workflowscheme/project?projectId="+url.QueryEscape(p.ID), &schemes); err != nil {
+		return nil, fmt.Errorf("fetching workflow scheme of project %s: %v", project, err)
+	}
+	var names []string
+	for _, v := range schemes.Values {
+		scheme := v.WorkflowScheme
+		workflows := []string{scheme.DefaultWorkflow}
+		for _, name := range scheme.IssueTypeMappings {
+			workflows = append(workflows, name)
+		}
+		for _, name := range workflows {
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var transitions []WorkflowTransition
+	for startAt, last := 0, len(names) == 0; !last; {
+		query := url.Values{"workflowName": names, "expand": {"transitions,statuses"}, "startAt": {fmt.Sprint(startAt)}}
+		var page workflowPage
+		if err := c.get(ctx, "rest/api/2/workflow/search?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("fetching workflows of project %s: %v", project, err)
+		}
+		for _, w := range page.Values {
+			statuses := make(map[string]string, len(w.Statuses))
+			for _, s := range w.Statuses {
+				statuses[s.ID] = s.Name
+			}
+			for _, t := range w.Transitions {
+				if t.Type == "initial" {
+					continue
+				}
+				wt := WorkflowTransition{Name: t.Name, To: statuses[t.To]}
+				for _, from := range t.From {
+					wt.From = append(wt.From, statuses[from])
+				}
+				transitions = append(transitions, wt)
+			}
+		}
+		startAt += len(page.Values)
+		last = page.IsLast || len(page.Values) == 0
+	}
+	return transitions, nil
+}
+
+// get decodes the response of a GET request to the jira API
+func (c *Connection) get(ctx context.Context, path string, v interface{}) error {
+	req, err := c.Client.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	response, err := c.Client.Do(req, v)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
@@ -0,0 +1,164 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/jira/mock"
+)
+
+func TestConnection_GetProjectStatuses(t *testing.T) {
+	todo := gojira.Status{Name: "To Do", StatusCategory: gojira.StatusCategory{Key: "new", Name: "To Do"}}
+	inProgress := gojira.Status{Name: "In Progress", StatusCategory: gojira.StatusCategory{Key: "indeterminate", Name: "In Progress"}}
+	done := gojira.Status{Name: "Done", StatusCategory: gojira.StatusCategory{Key: "done", Name: "Done"}}
+
+	tests := []struct {
+		name     string
+		project  string
+		handler  http.HandlerFunc
+		expected []string
+		errMatch string
+	}{
+		{
+			name:    "statuses of all issue types without duplicates",
+			project: "OPECO",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "OPECO", mux.Vars(r)["project"])
+				w.Write(mock.MustMarshal([]issueTypeStatuses{
+					{Name: "Story", Statuses: []gojira.Status{todo, inProgress, done}},
+					{Name: "Bug", Statuses: []gojira.Status{todo, {Name: "done"}, {Name: "Verified"}}},
+				}))
+			},
+			expected: []string{"To Do", "In Progress", "Done", "Verified"},
+		},
+		{
+			name:    "unknown project",
+			project: "NOPE",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "no project")
+			},
+			errMatch: "fetching statuses of project NOPE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := gojira.NewClient(mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(mock.GetProjectStatusesByProject, tt.handler),
+			), "https://jira.example.com/")
+			require.NoError(t, err)
+			c := &Connection{Client: client, baseUri: "https://jira.example.com/"}
+
+			statuses, err := c.GetProjectStatuses(context.Background(), tt.project)
+			if tt.errMatch != "" {
+				require.ErrorContains(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, s := range statuses {
+				names = append(names, s.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestConnection_GetProjectTransitions(t *testing.T) {
+	project := mock.WithRequestMatchHandler(mock.GetProjectByProject, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(mock.MustMarshal(gojira.Project{ID: "10001", Key: mux.Vars(r)["project"]}))
+	}))
+	schemes := mock.WithRequestMatchHandler(mock.GetWorkflowSchemeProject, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "10001", r.URL.Query().Get("projectId"))
+		w.Write([]byte(`{"values":[{"projectIds":["10001"],"workflowScheme":{"defaultWorkflow":"basic","issueTypeMappings":{"10002":"bugs","10003":"basic"}}}]}`))
+	}))
+	pages := []string{
+		`{"isLast":false,"values":[{"id":{"name":"basic"},
+			"statuses":[{"id":"1","name":"To Do"},{"id":"3","name":"In Progress"},{"id":"10000","name":"Done"}],
+			"transitions":[
+				{"name":"Create","from":[],"to":"1","type":"initial"},
+				{"name":"Start","from":["1"],"to":"3","type":"directed"},
+				{"name":"Close","from":[],"to":"10000","type":"global"}]}]}`,
+		`{"isLast":true,"values":[{"id":{"name":"bugs"},
+			"statuses":[{"id":"1","name":"To Do"},{"id":"10001","name":"Verified"}],
+			"transitions":[{"name":"Verify","from":["1"],"to":"10001","type":"directed"}]}]}`,
+	}
+
+	tests := []struct {
+		name     string
+		options  []mock.MockBackendOption
+		expected []WorkflowTransition
+		errMatch string
+	}{
+		{
+			name: "transitions of every workflow of the scheme",
+			options: []mock.MockBackendOption{project, schemes,
+				mock.WithRequestMatchHandler(mock.GetWorkflowSearch, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, []string{"basic", "bugs"}, r.URL.Query()["workflowName"])
+					require.Equal(t, "transitions,statuses", r.URL.Query().Get("expand"))
+					switch r.URL.Query().Get("startAt") {
+					case "0":
+						w.Write([]byte(pages[0]))
+					case "1":
+						w.Write([]byte(pages[1]))
+					default:
+						t.Errorf("unexpected page at %s", r.URL.Query().Get("startAt"))
+					}
+				})),
+			},
+			expected: []WorkflowTransition{
+				{Name: "Start", From: []string{"To Do"}, To: "In Progress"},
+				{Name: "Close", To: "Done"},
+				{Name: "Verify", From: []string{"To Do"}, To: "Verified"},
+			},
+		},
+		{
+			name: "workflows readable by administrators only",
+			options: []mock.MockBackendOption{project, schemes,
+				mock.WithRequestMatchHandler(mock.GetWorkflowSearch, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mock.WriteError(w, http.StatusForbidden, "forbidden")
+				})),
+			},
+			errMatch: "fetching workflows of project OPECO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := gojira.NewClient(mock.NewMockedHTTPClient(tt.options...), "https://jira.example.com/")
+			require.NoError(t, err)
+			c := &Connection{Client: client, baseUri: "https://jira.example.com/"}
+
+			transitions, err := c.GetProjectTransitions(context.Background(), "OPECO")
+			if tt.errMatch != "" {
+				require.ErrorContains(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, transitions)
+		})
+	}
+}
+
+func TestStatusCategory(t *testing.T) {
+	require.Equal(t, "Done", StatusCategory(&gojira.Status{StatusCategory: gojira.StatusCategory{Key: "done", Name: "Done"}}))
+	require.Equal(t, "done", StatusCategory(&gojira.Status{StatusCategory: gojira.StatusCategory{Key: "done"}}))
+	require.Equal(t, "", StatusCategory(nil))
+}
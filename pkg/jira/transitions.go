@@ -0,0 +1,67 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jira
+
+import (
+	"context"
+	"strings"
+
+	gojira "github.com/andygrunwald/go-jira"
+)
+
+// FindTransition returns the first transition available to the issue which reaches one of the
+// given statuses, trying statuses in order of preference; failing that, the first transition
+// whose target status is accepted, or nil if none is reachable. Status names are case-insensitive.
+func (c *Connection) FindTransition(ctx context.Context, key string, statuses []string, accept func(gojira.Status) bool) (*gojira.Transition, error) {
+	transitions, response, err := c.Client.Issue.GetTransitionsWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	for _, status := range statuses {
+		for i := range transitions {
+			if strings.EqualFold(transitions[i].To.Name, status) {
+				return &transitions[i], nil
+			}
+		}
+	}
+	if accept != nil {
+		for i := range transitions {
+			if accept(transitions[i].To) {
+				return &transitions[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// DoTransition applies the transition to the issue
+func (c *Connection) DoTransition(ctx context.Context, key string, transition *gojira.Transition) error {
+	response, err := c.Client.Issue.DoTransitionWithContext(ctx, key, transition.ID)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return nil
+}
+
+// AddComment adds a comment to the issue
+func (c *Connection) AddComment(ctx context.Context, key string, body string) error {
+	_, response, err := c.Client.Issue.AddCommentWithContext(ctx, key, &gojira.Comment{Body: body})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return nil
+}
@@ -0,0 +1,80 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+
+	"github.com/oceanc80/gh2jira/pkg/gh"
+)
+
+// BrokenReason classifies why the github issue of a jira remote link couldn't be evaluated
+type BrokenReason string
+
+const (
+	BrokenMalformed   BrokenReason = "malformed-link"
+	BrokenNotFound    BrokenReason = "not-found"
+	BrokenDeleted     BrokenReason = "deleted"
+	BrokenForbidden   BrokenReason = "forbidden"
+	BrokenTransferred BrokenReason = "transferred"
+	BrokenError       BrokenReason = "error"
+)
+
+type BrokenLink struct {
+	Reason  BrokenReason `json:"reason"`
+	Message string       `json:"message"`
+}
+
+// classifyGithubError turns a failure to get a linked github issue into a broken link.
+// Errors which would affect every link alike (rate limiting, cancellation) are returned as fatal instead.
+func classifyGithubError(err error) (*BrokenLink, error) {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		switch respErr.Response.StatusCode {
+		case http.StatusNotFound:
+			return &BrokenLink{Reason: BrokenNotFound, Message: "issue not found; the repository may be private or renamed, or the issue never existed"}, nil
+		case http.StatusGone:
+			return &BrokenLink{Reason: BrokenDeleted, Message: "issue was deleted"}, nil
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return &BrokenLink{Reason: BrokenForbidden, Message: fmt.Sprintf("access denied: %s", respErr.Message)}, nil
+		}
+	}
+
+	return &BrokenLink{Reason: BrokenError, Message: err.Error()}, nil
+}
+
+// checkTransferred detects a linked issue which github redirected to another repository or issue number
+func checkTransferred(gi *github.Issue, project string, num int) *BrokenLink {
+	if gi.GetHTMLURL() == "" {
+		return nil
+	}
+	got, err := gh.ParseLink(gi.GetHTMLURL())
+	if err != nil {
+		return nil
+	}
+	if !strings.EqualFold(got.Project, project) || got.Number != num {
+		return &BrokenLink{Reason: BrokenTransferred, Message: fmt.Sprintf("issue was transferred to %s", gi.GetHTMLURL())}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyGithubError(t *testing.T) {
+	responseErr := func(status int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status}, Message: "oops"}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		reason    BrokenReason
+		wantFatal bool
+	}{
+		{name: "not found", err: responseErr(http.StatusNotFound), reason: BrokenNotFound},
+		{name: "deleted", err: responseErr(http.StatusGone), reason: BrokenDeleted},
+		{name: "forbidden", err: responseErr(http.StatusForbidden), reason: BrokenForbidden},
+		{name: "server error", err: responseErr(http.StatusInternalServerError), reason: BrokenError},
+		{name: "other error", err: errors.New("connection reset"), reason: BrokenError},
+		{name: "rate limited", err: &github.RateLimitError{Message: "slow down"}, wantFatal: true},
+		{name: "canceled", err: fmt.Errorf("get issue: %w", context.Canceled), wantFatal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := classifyGithubError(tt.err)
+			if tt.wantFatal {
+				require.Error(t, err)
+				require.Nil(t, b)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.reason, b.Reason)
+		})
+	}
+}
+
+func TestCheckTransferred(t *testing.T) {
+	issue := func(url string) *github.Issue {
+		return &github.Issue{HTMLURL: github.String(url)}
+	}
+
+	require.Nil(t, checkTransferred(issue("https://github.com/fakeorg/fakeproject/issues/12"), "FakeOrg/fakeproject", 12))
+	require.Nil(t, checkTransferred(&github.Issue{}, "fakeorg/fakeproject", 12))
+
+	b := checkTransferred(issue("https://github.com/fakeorg/otherproject/issues/3"), "fakeorg/fakeproject", 12)
+	require.NotNil(t, b)
+	require.Equal(t, BrokenTransferred, b.Reason)
+	require.Contains(t, b.Message, "fakeorg/otherproject/issues/3")
+}
@@ -0,0 +1,119 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dimension is an aspect of a pair of issues which is compared when reconciling
+type Dimension string
+
+const (
+	// DimensionState compares the github state with the jira status through the workflow
+	DimensionState Dimension = "state"
+	// DimensionAssignee compares the github assignee with the github login of the jira assignee
+	DimensionAssignee Dimension = "assignee"
+	// DimensionTitle checks that the jira summary contains the github title
+	DimensionTitle Dimension = "title"
+	// DimensionLabels checks that each github label is also a jira label
+	DimensionLabels Dimension = "labels"
+	// DimensionMilestone checks that the jira version mapped to the github milestone is one of the jira fix versions
+	DimensionMilestone Dimension = "milestone"
+)
+
+// Dimensions lists the supported dimensions, in the order they are reported
+var Dimensions = []Dimension{DimensionState, DimensionAssignee, DimensionTitle, DimensionLabels, DimensionMilestone}
+
+// DefaultDimensions are the dimensions compared unless others are selected
+var DefaultDimensions = []Dimension{DimensionState}
+
+// ParseDimensions validates dimension names, returning them without duplicates in the order of Dimensions
+func ParseDimensions(names []string) ([]Dimension, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no dimensions selected")
+	}
+	selected := map[Dimension]bool{}
+	for _, n := range names {
+		d := Dimension(strings.ToLower(strings.TrimSpace(n)))
+		if !slices.Contains(Dimensions, d) {
+			return nil, fmt.Errorf("invalid dimension %q (accepted dimensions are %s)", n, dimensionList())
+		}
+		selected[d] = true
+	}
+	dims := []Dimension{}
+	for _, d := range Dimensions {
+		if selected[d] {
+			dims = append(dims, d)
+		}
+	}
+	return dims, nil
+}
+
+func dimensionList() string {
+	quoted := make([]string, 0, len(Dimensions))
+	for _, d := range Dimensions {
+		quoted = append(quoted, fmt.Sprintf("%q", d))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Outcomes records the outcome of each compared dimension of a pair
+type Outcomes map[Dimension]Outcome
+
+// Mismatched returns the dimensions which don't match, in the order of Dimensions
+func (o Outcomes) Mismatched() []Dimension {
+	dims := []Dimension{}
+	for _, d := range Dimensions {
+		if o[d] == OutcomeMismatch {
+			dims = append(dims, d)
+		}
+	}
+	return dims
+}
+
+// outcome converts a comparison into its outcome
+func outcome(match bool) Outcome {
+	if match {
+		return OutcomeMatch
+	}
+	return OutcomeMismatch
+}
+
+// sameTitle reports whether the jira summary contains the github title; summaries of cloned
+// issues usually decorate the title, e.g.: [UPSTREAM] title #123
+func sameTitle(summary, title string) bool {
+	return strings.Contains(strings.ToLower(summary), strings.ToLower(strings.TrimSpace(title)))
+}
+
+// sameLabels reports whether each github label is also a jira label.  Jira labels can't contain
+// spaces, so those of github labels match dashes; additional jira labels are ignored.
+func sameLabels(jiraLabels, githubLabels []string) bool {
+	for _, gl := range githubLabels {
+		want := strings.Join(strings.Fields(gl), "-")
+		if !slices.ContainsFunc(jiraLabels, func(jl string) bool { return strings.EqualFold(jl, want) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameVersion reports whether the version mapped to the github milestone is one of the jira fix versions,
+// or whether neither issue is scheduled
+func sameVersion(fixVersions []string, version string) bool {
+	if version == "" {
+		return len(fixVersions) == 0
+	}
+	return slices.ContainsFunc(fixVersions, func(v string) bool { return strings.EqualFold(v, version) })
+}
@@ -0,0 +1,64 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDimensions(t *testing.T) {
+	dims, err := ParseDimensions([]string{"Milestone", "state", " assignee", "state"})
+	require.NoError(t, err)
+	require.Equal(t, []Dimension{DimensionState, DimensionAssignee, DimensionMilestone}, dims)
+
+	_, err = ParseDimensions([]string{"state", "priority"})
+	require.EqualError(t, err, `invalid dimension "priority" (accepted dimensions are "state", "assignee", "title", "labels", "milestone")`)
+
+	_, err = ParseDimensions(nil)
+	require.Error(t, err)
+}
+
+func TestOutcomes_Mismatched(t *testing.T) {
+	o := Outcomes{
+		DimensionMilestone: OutcomeMismatch,
+		DimensionState:     OutcomeMatch,
+		DimensionAssignee:  OutcomeMismatch,
+	}
+	require.Equal(t, []Dimension{DimensionAssignee, DimensionMilestone}, o.Mismatched())
+	require.Empty(t, Outcomes{}.Mismatched())
+}
+
+func TestCompareDimensions(t *testing.T) {
+	tests := []struct {
+		name  string
+		match bool
+		same  func() bool
+	}{
+		{name: "title in decorated summary", match: true, same: func() bool { return sameTitle("[UPSTREAM] Crash on start #12", "crash on start ") }},
+		{name: "different title", match: false, same: func() bool { return sameTitle("[UPSTREAM] Crash on exit #12", "crash on start") }},
+		{name: "labels with spaces", match: true, same: func() bool { return sameLabels([]string{"Good-First-Issue", "upstream"}, []string{"good first issue"}) }},
+		{name: "no github labels", match: true, same: func() bool { return sameLabels([]string{"upstream"}, nil) }},
+		{name: "missing label", match: false, same: func() bool { return sameLabels([]string{"upstream"}, []string{"kind/bug"}) }},
+		{name: "version in fix versions", match: true, same: func() bool { return sameVersion([]string{"v1.33.0", "V1.34.0"}, "v1.34.0") }},
+		{name: "neither scheduled", match: true, same: func() bool { return sameVersion(nil, "") }},
+		{name: "only jira scheduled", match: false, same: func() bool { return sameVersion([]string{"v1.34.0"}, "") }},
+		{name: "only github scheduled", match: false, same: func() bool { return sameVersion(nil, "v1.34.0") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.match, tt.same())
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/oceanc80/gh2jira/pkg/gh"
+	"github.com/oceanc80/gh2jira/pkg/jira"
+	"github.com/oceanc80/gh2jira/pkg/workflow"
+)
+
+// Direction names the source of truth when fixing mismatches; the other side is changed to match it
+type Direction string
+
+const (
+	// DirectionGithub transitions jira issues to match the state of their github issues
+	DirectionGithub Direction = "github"
+	// DirectionJira closes or reopens github issues to match the status of their jira issues
+	DirectionJira Direction = "jira"
+)
+
+// FixResult records the action taken (or, in dry run mode, planned) to resolve a mismatched pair
+type FixResult struct {
+	Pair    PairResult `json:"pair"`
+	Issue   string     `json:"issue"`
+	Action  string     `json:"action,omitempty"`
+	From    string     `json:"from,omitempty"`
+	To      string     `json:"to,omitempty"`
+	Applied bool       `json:"applied"`
+	Error   string     `json:"error,omitempty"`
+}
+
+type FixSpec struct {
+	dryRun    bool
+	comment   bool
+	direction Direction
+	workflow  *workflow.Workflow
+}
+
+type FixOption func(*FixSpec) error
+
+func WithDryRun(dryRun bool) FixOption {
+	return func(s *FixSpec) error {
+		s.dryRun = dryRun
+		return nil
+	}
+}
+
+// WithComment adds a comment explaining the automated change to each fixed jira issue.
+// Github issues are always commented since the change is made on behalf of another system.
+func WithComment(comment bool) FixOption {
+	return func(s *FixSpec) error {
+		s.comment = comment
+		return nil
+	}
+}
+
+// WithFixWorkflow selects the state mappings used to pick the target state of each fix;
+// without it the default workflow of the default workflow file is used
+func WithFixWorkflow(w *workflow.Workflow) FixOption {
+	return func(s *FixSpec) error {
+		s.workflow = w
+		return nil
+	}
+}
+
+func WithDirection(direction Direction) FixOption {
+	return func(s *FixSpec) error {
+		switch direction {
+		case DirectionGithub, DirectionJira:
+			s.direction = direction
+			return nil
+		default:
+			return fmt.Errorf("invalid direction %q (accepted directions are %q, %q)", direction, DirectionGithub, DirectionJira)
+		}
+	}
+}
+
+// Fix resolves each mismatched pair by changing the side which isn't the source of truth: by default the jira issue
+// is transitioned to a status which the workflow allows for the state of the linked github issue; in the jira
+// direction the github issue is closed or reopened instead.  Problems with individual issues are recorded in their
+// result rather than aborting the run.  Only state mismatches are fixed; pairs mismatched in other dimensions only
+// are skipped.
+func Fix(ctx context.Context, results *TypeResults, jc *jira.Connection, gc *gh.Connection, options ...FixOption) ([]FixResult, error) {
+	spec := &FixSpec{direction: DirectionGithub}
+	for _, opt := range options {
+		if err := opt(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	if jc == nil || gc == nil {
+		return nil, errors.New("nil connection")
+	}
+
+	if spec.workflow == nil {
+		var err error
+		spec.workflow, err = workflow.LoadWorkflow("", "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fixes := make([]FixResult, 0, len(results.Mismatches))
+	for _, pair := range results.Mismatches {
+		if pair.Outcomes[DimensionState] != OutcomeMismatch {
+			continue
+		}
+		if spec.direction == DirectionJira {
+			fixes = append(fixes, fixGithub(pair, gc, spec))
+			continue
+		}
+
+		fix := FixResult{Pair: pair, Issue: pair.Jira.Name, From: pair.Jira.Status}
+
+		jstates, err := spec.workflow.JiraStates(pair.Git.Status, pair.Git.StateReason)
+		if err != nil {
+			fix.Error = err.Error()
+			fixes = append(fixes, fix)
+			continue
+		}
+
+		// statuses named by the mapping are preferred, in order, over those matched by pattern or category
+		transition, err := jc.FindTransition(ctx, pair.Jira.Name, jstates, func(to gojira.Status) bool {
+			ok, _ := spec.workflow.ValidateState(pair.Git.Status, pair.Git.StateReason, workflow.JiraStatus{Name: to.Name, Category: jira.StatusCategory(&to)})
+			return ok
+		})
+		if err != nil {
+			fix.Error = err.Error()
+			fixes = append(fixes, fix)
+			continue
+		}
+		if transition == nil {
+			fix.Error = fmt.Sprintf("no transition from %q to a status mapped to %q", pair.Jira.Status, githubState(pair.Git).String())
+			fixes = append(fixes, fix)
+			continue
+		}
+		fix.Action = fmt.Sprintf("transition %q", transition.Name)
+		fix.To = transition.To.Name
+
+		if !spec.dryRun {
+			if err := jc.DoTransition(ctx, pair.Jira.Name, transition); err != nil {
+				fix.Error = err.Error()
+				fixes = append(fixes, fix)
+				continue
+			}
+			fix.Applied = true
+
+			if spec.comment {
+				body := fmt.Sprintf("gh2jira automatically transitioned this issue from %q to %q to match the %q state of the linked github issue [%s|%s].",
+					fix.From, fix.To, githubState(pair.Git).String(), pair.Git.Name, pair.Git.Link)
+				if err := jc.AddComment(ctx, pair.Jira.Name, body); err != nil {
+					fix.Error = fmt.Sprintf("transitioned, but unable to add comment: %v", err)
+				}
+			}
+		}
+
+		fixes = append(fixes, fix)
+	}
+
+	return fixes, nil
+}
+
+// Resolve returns the results as they stand after the given fixes: each pair whose fix was applied takes the
+// status it was changed to and matches in state, moving to the matches unless other dimensions still mismatch.
+// Dry-run and failed fixes leave their pairs unchanged.
+func Resolve(results *TypeResults, fixes []FixResult) *TypeResults {
+	applied := make(map[string]FixResult)
+	for _, f := range fixes {
+		if f.Applied {
+			applied[f.Pair.Jira.Name+"|"+f.Pair.Git.Name] = f
+		}
+	}
+
+	resolved := *results
+	resolved.Matches = slices.Clone(results.Matches)
+	resolved.Mismatches = make(PairResults, 0, len(results.Mismatches))
+	for _, pair := range results.Mismatches {
+		fix, ok := applied[pair.Jira.Name+"|"+pair.Git.Name]
+		if !ok {
+			resolved.Mismatches = append(resolved.Mismatches, pair)
+			continue
+		}
+		if fix.Issue == pair.Jira.Name {
+			pair.Jira.Status = fix.To
+		} else {
+			state, reason, _ := strings.Cut(fix.To, " (")
+			pair.Git.Status, pair.Git.StateReason = state, strings.TrimSuffix(reason, ")")
+		}
+		pair.Outcomes = maps.Clone(pair.Outcomes)
+		pair.Outcomes[DimensionState] = OutcomeMatch
+		if len(pair.Outcomes.Mismatched()) > 0 {
+			resolved.Mismatches = append(resolved.Mismatches, pair)
+		} else {
+			resolved.Matches = append(resolved.Matches, pair)
+		}
+	}
+	return &resolved
+}
+
+// fixGithub closes or reopens the github issue of the pair to match the status of its jira issue
+func fixGithub(pair PairResult, gc *gh.Connection, spec *FixSpec) FixResult {
+	current := githubState(pair.Git)
+	fix := FixResult{Pair: pair, Issue: pair.Git.Name, From: current.String()}
+
+	target, err := spec.workflow.GithubState(jiraStatus(pair.Jira))
+	if err != nil {
+		fix.Error = err.Error()
+		return fix
+	}
+	fix.To = target.String()
+	if current.State == workflow.StateMerged || target.State == workflow.StateMerged {
+		fix.Error = "merged pull requests can't be changed, and pull requests can't be merged by gh2jira"
+		return fix
+	}
+	if pair.Git.Pull {
+		// pull requests have no state reason
+		target.Reason = ""
+	}
+	if target.State == current.State && (target.Reason == "" || target.Reason == current.Reason) {
+		fix.Error = fmt.Sprintf("github issue is already %s", current)
+		return fix
+	}
+	fix.Action = "reopen"
+	if target.State == "closed" {
+		fix.Action = "close"
+	}
+	if target.Reason != "" {
+		fix.Action = fmt.Sprintf("%s as %s", fix.Action, target.Reason)
+	}
+
+	project, num, err := pair.Git.IssueRef()
+	if err != nil {
+		fix.Error = err.Error()
+		return fix
+	}
+
+	if spec.dryRun {
+		return fix
+	}
+
+	if _, err := gc.SetIssueState(num, target.State, target.Reason, gh.WithProject(project)); err != nil {
+		fix.Error = err.Error()
+		return fix
+	}
+	fix.Applied = true
+
+	verb := "Reopened"
+	if target.State == "closed" {
+		verb = "Closed"
+	}
+	body := fmt.Sprintf("%s automatically by gh2jira to match the %q status of the downstream jira issue [%s](%s).",
+		verb, pair.Jira.Status, pair.Jira.Name, pair.Jira.Link)
+	if err := gc.AddComment(num, body, gh.WithProject(project)); err != nil {
+		fix.Error = fmt.Sprintf("%s, but unable to add comment: %v", strings.ToLower(verb), err)
+	}
+
+	return fix
+}
+
+// githubState is the state, qualified by its reason, of a github issue
+func githubState(s IssueStatus) workflow.GithubState {
+	return workflow.GithubState{State: s.Status, Reason: s.StateReason}
+}
+
+// jiraStatus is the status, with its category, of a jira issue
+func jiraStatus(s IssueStatus) workflow.JiraStatus {
+	return workflow.JiraStatus{Name: s.Status, Category: s.Category}
+}
@@ -0,0 +1,231 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+	ghmock "github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/jira/mock"
+)
+
+func TestFix(t *testing.T) {
+	// mismatched returns a pair of a jira issue and a github issue mismatched in the given dimensions
+	mismatched := func(jstatus, ghstate string, dims ...Dimension) PairResult {
+		outcomes := Outcomes{}
+		for _, d := range dims {
+			outcomes[d] = OutcomeMismatch
+		}
+		return PairResult{
+			Jira:     IssueStatus{Name: "OPECO-1", Status: jstatus, Link: "https://jira.example.com/browse/OPECO-1"},
+			Git:      IssueStatus{Name: "org/repo/1", Status: ghstate, Link: "https://github.com/org/repo/issues/1"},
+			Outcomes: outcomes,
+		}
+	}
+	transitions := []gojira.Transition{
+		{ID: "11", Name: "Start", To: gojira.Status{Name: "In Progress"}},
+		{ID: "21", Name: "Close", To: gojira.Status{Name: "Done"}},
+	}
+
+	tests := []struct {
+		name        string
+		pair        PairResult
+		options     []FixOption
+		transitions []gojira.Transition
+		expected    []FixResult
+		requests    []string
+	}{
+		{
+			name:        "transition jira issue",
+			pair:        mismatched("In Progress", "closed", DimensionState),
+			transitions: transitions,
+			expected:    []FixResult{{Issue: "OPECO-1", Action: `transition "Close"`, From: "In Progress", To: "Done", Applied: true}},
+			requests: []string{
+				`POST /rest/api/2/issue/OPECO-1/transitions {"transition":{"id":"21"}}`,
+			},
+		},
+		{
+			name:        "transition jira issue with comment",
+			pair:        mismatched("In Progress", "closed", DimensionState),
+			options:     []FixOption{WithComment(true)},
+			transitions: transitions,
+			expected:    []FixResult{{Issue: "OPECO-1", Action: `transition "Close"`, From: "In Progress", To: "Done", Applied: true}},
+			requests: []string{
+				`POST /rest/api/2/issue/OPECO-1/transitions {"transition":{"id":"21"}}`,
+				`POST /rest/api/2/issue/OPECO-1/comment {"body":"gh2jira automatically transitioned this issue from \"In Progress\" to \"Done\" to match the \"closed\" state of the linked github issue [org/repo/1|https://github.com/org/repo/issues/1]."}`,
+			},
+		},
+		{
+			name:        "transition jira issue dry run",
+			pair:        mismatched("In Progress", "closed", DimensionState),
+			options:     []FixOption{WithDryRun(true), WithComment(true)},
+			transitions: transitions,
+			expected:    []FixResult{{Issue: "OPECO-1", Action: `transition "Close"`, From: "In Progress", To: "Done"}},
+		},
+		{
+			name:        "missing transition",
+			pair:        mismatched("Done", "open", DimensionState),
+			transitions: transitions[1:],
+			expected:    []FixResult{{Issue: "OPECO-1", From: "Done", Error: `no transition from "Done" to a status mapped to "open"`}},
+		},
+		{
+			name:    "only state mismatches are fixed",
+			pair:    mismatched("In Progress", "open", DimensionAssignee),
+			options: []FixOption{WithComment(true)},
+		},
+		{
+			name:     "close github issue",
+			pair:     mismatched("Done", "open", DimensionState),
+			options:  []FixOption{WithDirection(DirectionJira)},
+			expected: []FixResult{{Issue: "org/repo/1", Action: "close", From: "open", To: "closed", Applied: true}},
+			requests: []string{
+				`PATCH /repos/org/repo/issues/1 {"state":"closed"}`,
+				`POST /repos/org/repo/issues/1/comments {"body":"Closed automatically by gh2jira to match the \"Done\" status of the downstream jira issue [OPECO-1](https://jira.example.com/browse/OPECO-1)."}`,
+			},
+		},
+		{
+			name:     "reopen github issue",
+			pair:     mismatched("In Progress", "closed", DimensionState),
+			options:  []FixOption{WithDirection(DirectionJira)},
+			expected: []FixResult{{Issue: "org/repo/1", Action: "reopen", From: "closed", To: "open", Applied: true}},
+			requests: []string{
+				`PATCH /repos/org/repo/issues/1 {"state":"open"}`,
+				`POST /repos/org/repo/issues/1/comments {"body":"Reopened automatically by gh2jira to match the \"In Progress\" status of the downstream jira issue [OPECO-1](https://jira.example.com/browse/OPECO-1)."}`,
+			},
+		},
+		{
+			name:     "close github issue dry run",
+			pair:     mismatched("Done", "open", DimensionState),
+			options:  []FixOption{WithDirection(DirectionJira), WithDryRun(true)},
+			expected: []FixResult{{Issue: "org/repo/1", Action: "close", From: "open", To: "closed"}},
+		},
+		{
+			name:     "github issue already in state",
+			pair:     mismatched("Done", "closed", DimensionState),
+			options:  []FixOption{WithDirection(DirectionJira)},
+			expected: []FixResult{{Issue: "org/repo/1", From: "closed", To: "closed", Error: "github issue is already closed"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// record the changes requested, leaving out the empty fields which go-jira sends
+			var requests []string
+			record := func(r *http.Request) {
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				change := map[string]interface{}{}
+				for _, k := range []string{"transition", "body", "state", "state_reason"} {
+					if v, ok := body[k]; ok {
+						change[k] = v
+					}
+				}
+				b, err := json.Marshal(change)
+				require.NoError(t, err)
+				requests = append(requests, r.Method+" "+r.URL.Path+" "+string(b))
+			}
+
+			jc := newJiraConnection(t, nil, nil,
+				mock.WithRequestMatchHandler(mock.GetIssueTransitionsByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write(mock.MustMarshal(map[string]interface{}{"transitions": tt.transitions}))
+				})),
+				mock.WithRequestMatchHandler(mock.PostIssueTransitionsByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(r)
+					w.WriteHeader(http.StatusNoContent)
+				})),
+				mock.WithRequestMatchHandler(mock.PostIssueCommentByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(r)
+					w.WriteHeader(http.StatusCreated)
+					w.Write(mock.MustMarshal(gojira.Comment{ID: "1"}))
+				})),
+			)
+			gc := newGithubConnection(t, "",
+				ghmock.WithRequestMatchHandler(ghmock.PatchReposIssuesByOwnerByRepoByIssueNumber, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(r)
+					w.Write(ghmock.MustMarshal(github.Issue{Number: github.Int(1)}))
+				})),
+				ghmock.WithRequestMatchHandler(ghmock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(r)
+					w.Write(ghmock.MustMarshal(github.IssueComment{ID: github.Int64(1)}))
+				})),
+			)
+
+			results := &TypeResults{Mismatches: PairResults{tt.pair}}
+			fixes, err := Fix(context.Background(), results, jc, gc, append([]FixOption{WithFixWorkflow(testWorkflow)}, tt.options...)...)
+			require.NoError(t, err)
+			for i := range fixes {
+				require.Equal(t, tt.pair, fixes[i].Pair)
+				fixes[i].Pair = PairResult{}
+			}
+			if tt.expected == nil {
+				require.Empty(t, fixes)
+			} else {
+				require.Equal(t, tt.expected, fixes)
+			}
+			require.Equal(t, tt.requests, requests)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	pair := func(n string, jstatus, ghstate string, outcomes Outcomes) PairResult {
+		return PairResult{
+			Jira:     IssueStatus{Name: "OPECO-" + n, Status: jstatus},
+			Git:      IssueStatus{Name: "org/repo/" + n, Status: ghstate},
+			Outcomes: outcomes,
+		}
+	}
+	stateMismatch := Outcomes{DimensionState: OutcomeMismatch}
+	bothMismatch := Outcomes{DimensionState: OutcomeMismatch, DimensionTitle: OutcomeMismatch}
+	matched := pair("1", "Done", "closed", Outcomes{DimensionState: OutcomeMatch})
+	transitioned := pair("2", "In Progress", "closed", stateMismatch)
+	closed := pair("3", "Done", "open", stateMismatch)
+	retitled := pair("4", "In Progress", "closed", bothMismatch)
+	planned := pair("5", "In Progress", "closed", stateMismatch)
+	results := &TypeResults{
+		Matches:    PairResults{matched},
+		Mismatches: PairResults{transitioned, closed, retitled, planned},
+	}
+
+	resolved := Resolve(results, []FixResult{
+		{Pair: transitioned, Issue: "OPECO-2", From: "In Progress", To: "Done", Applied: true},
+		{Pair: closed, Issue: "org/repo/3", From: "open", To: "closed (completed)", Applied: true},
+		{Pair: retitled, Issue: "OPECO-4", From: "In Progress", To: "Done", Applied: true},
+		{Pair: planned, Issue: "OPECO-5", From: "In Progress", To: "Done"},
+	})
+
+	require.Equal(t, PairResults{
+		matched,
+		pair("2", "Done", "closed", Outcomes{DimensionState: OutcomeMatch}),
+		{
+			Jira:     IssueStatus{Name: "OPECO-3", Status: "Done"},
+			Git:      IssueStatus{Name: "org/repo/3", Status: "closed", StateReason: "completed"},
+			Outcomes: Outcomes{DimensionState: OutcomeMatch},
+		},
+	}, resolved.Matches)
+	require.Equal(t, PairResults{
+		pair("4", "Done", "closed", Outcomes{DimensionState: OutcomeMatch, DimensionTitle: OutcomeMismatch}),
+		planned,
+	}, resolved.Mismatches)
+
+	// the results themselves are left as they were
+	require.Equal(t, PairResults{matched}, results.Matches)
+	require.Equal(t, stateMismatch, results.Mismatches[0].Outcomes)
+}
@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+
+	"github.com/oceanc80/gh2jira/pkg/gh"
+	"github.com/oceanc80/gh2jira/pkg/jira"
+)
+
+// findOrphans lists the open issues of the github project which aren't linked from any issue of the jira project.
+// Jira issues in every status are considered, since a github issue whose clone was closed isn't an orphan.  The
+// remote links of the known issues, which were already fetched, aren't fetched again.
+func findOrphans(ctx context.Context, jc *jira.Connection, gc *gh.Connection, spec *ReconcileSpec, known map[string][]gojira.RemoteLink) ([]IssueStatus, error) {
+	jiraIssues, err := jc.SearchIssues(fmt.Sprintf("project=%s", spec.orphanJira))
+	if err != nil {
+		return nil, err
+	}
+	clones := make(jira.CloneIndex)
+	var unknown []gojira.Issue
+	for _, ji := range jiraIssues {
+		rlinks, ok := known[ji.Key]
+		if !ok {
+			unknown = append(unknown, ji)
+			continue
+		}
+		clones.AddLinks(rlinks, ji.Key)
+	}
+	if err := jc.AddClones(ctx, clones, unknown, spec.workers); err != nil {
+		return nil, err
+	}
+
+	issues, err := gc.ListIssues(gh.WithProject(spec.orphanGithub))
+	if err != nil {
+		return nil, err
+	}
+
+	return orphanedIssues(issues, clones, spec.orphanGithub), nil
+}
+
+func orphanedIssues(issues []*github.Issue, clones jira.CloneIndex, githubProject string) []IssueStatus {
+	orphans := make([]IssueStatus, 0)
+	for _, gi := range issues {
+		if gi.IsPullRequest() {
+			continue
+		}
+		if _, ok := clones.Get(gi.GetHTMLURL()); ok {
+			continue
+		}
+
+		var assignee string = unassigned_issue
+		if gi.GetAssignee() != nil {
+			assignee = gi.GetAssignee().GetLogin()
+		}
+		orphans = append(orphans, IssueStatus{
+			Name:     fmt.Sprintf("%s/%d", githubProject, gi.GetNumber()),
+			Title:    gi.GetTitle(),
+			Status:   gi.GetState(),
+			Assignee: assignee,
+			Link:     gi.GetHTMLURL(),
+		})
+	}
+	return orphans
+}
+
+// IssueRef returns the github project and issue number of a github issue status
+func (s IssueStatus) IssueRef() (string, int, error) {
+	ref, err := gh.ParseLink(s.Link)
+	if err != nil {
+		return "", 0, err
+	}
+	return ref.Project, ref.Number, nil
+}
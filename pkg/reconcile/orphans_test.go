@@ -0,0 +1,66 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/jira"
+)
+
+func TestOrphanedIssues(t *testing.T) {
+	issues := []*github.Issue{
+		{
+			Number:  github.Int(1),
+			Title:   github.String("cloned"),
+			State:   github.String("open"),
+			HTMLURL: github.String("https://github.com/fakeorg/fakeproject/issues/1"),
+		},
+		{
+			Number:  github.Int(2),
+			Title:   github.String("never cloned"),
+			State:   github.String("open"),
+			HTMLURL: github.String("https://github.com/fakeorg/fakeproject/issues/2"),
+			Assignee: &github.User{
+				Login: github.String("someone"),
+			},
+		},
+		{
+			Number:           github.Int(3),
+			Title:            github.String("a pull request"),
+			State:            github.String("open"),
+			HTMLURL:          github.String("https://github.com/fakeorg/fakeproject/pull/3"),
+			PullRequestLinks: &github.PullRequestLinks{URL: github.String("https://api.github.com/repos/fakeorg/fakeproject/pulls/3")},
+		},
+	}
+	clones := jira.CloneIndex{}
+	clones.Add("https://www.github.com/FakeOrg/fakeproject/issues/1/", "FAKE-1")
+
+	orphans := orphanedIssues(issues, clones, "fakeorg/fakeproject")
+	require.Equal(t, []IssueStatus{
+		{
+			Name:     "fakeorg/fakeproject/2",
+			Title:    "never cloned",
+			Status:   "open",
+			Assignee: "someone",
+			Link:     "https://github.com/fakeorg/fakeproject/issues/2",
+		},
+	}, orphans)
+
+	project, num, err := orphans[0].IssueRef()
+	require.NoError(t, err)
+	require.Equal(t, "fakeorg/fakeproject", project)
+	require.Equal(t, 2, num)
+}
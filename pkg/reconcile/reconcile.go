@@ -15,34 +15,54 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
-	"slices"
-	"strconv"
 	"strings"
 
 	gojira "github.com/andygrunwald/go-jira"
+	"github.com/oceanc80/gh2jira/pkg/config"
 	"github.com/oceanc80/gh2jira/pkg/gh"
 	"github.com/oceanc80/gh2jira/pkg/jira"
+	"github.com/oceanc80/gh2jira/pkg/util"
 	"github.com/oceanc80/gh2jira/pkg/workflow"
 )
 
 const unassigned_issue string = "unassigned"
 
 type IssueStatus struct {
-	Name     string `json:"name"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Name        string   `json:"name"`
+	Title       string   `json:"title,omitempty"`
+	Status      string   `json:"status"`
+	StateReason string   `json:"stateReason,omitempty"` // github only, e.g.: not_planned
+	Category    string   `json:"category,omitempty"`    // jira only, the status category, e.g.: In Progress
+	Pull        bool     `json:"pull,omitempty"`        // github only, the link is to a pull request
+	Assignee    string   `json:"assignee"`
+	Labels      []string `json:"labels,omitempty"`
+	Milestone   string   `json:"milestone,omitempty"`   // github only
+	Version     string   `json:"version,omitempty"`     // github only, the jira version mapped to the milestone
+	FixVersions []string `json:"fixVersions,omitempty"` // jira only
+	Link        string   `json:"link,omitempty"`
+}
+
+// QualifiedStatus is the status followed by the state reason, if any, e.g.: closed (not_planned)
+func (s IssueStatus) QualifiedStatus() string {
+	if s.StateReason == "" {
+		return s.Status
+	}
+	return fmt.Sprintf("%s (%s)", s.Status, s.StateReason)
 }
 
 type PairResult struct {
-	Jira IssueStatus `json:"jira"`
-	Git  IssueStatus `json:"github"`
+	Jira     IssueStatus `json:"jira"`
+	Git      IssueStatus `json:"github"`
+	Outcomes Outcomes    `json:"outcomes,omitempty"` // of the compared dimensions; absent for broken links
+	Broken   *BrokenLink `json:"broken,omitempty"`
 }
 
 type PairResults []PairResult
 type TypeResults struct {
-	Matches    PairResults `json:"matches"`
-	Mismatches PairResults `json:"mismatches"`
+	Matches    PairResults   `json:"matches"`
+	Mismatches PairResults   `json:"mismatches"`
+	Broken     PairResults   `json:"broken"`
+	Orphans    []IssueStatus `json:"orphans,omitempty"`
 }
 
 type Outcome string
@@ -52,20 +72,131 @@ const (
 	OutcomeMismatch Outcome = "MISMATCH"
 )
 
-func Reconcile(ctx context.Context, jql string, jc *jira.Connection, gc *gh.Connection) (*TypeResults, error) {
+type ReconcileSpec struct {
+	users         *config.UserMapping
+	versions      *config.VersionMapping
+	orphanGithub  string
+	orphanJira    string
+	reportOrphans bool
+	workers       int
+	workflow      *workflow.Workflow
+	dimensions    []Dimension
+	links         *gh.LinkRecognizer
+}
+
+type ReconcileOption func(*ReconcileSpec) error
+
+// WithUserMapping translates jira assignees to github logins so that assignees can be compared
+func WithUserMapping(users *config.UserMapping) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		s.users = users
+		return nil
+	}
+}
+
+// WithVersionMapping maps github milestones to the jira versions which the milestone dimension expects
+// as fix version; without it milestones are expected to name the versions
+func WithVersionMapping(versions *config.VersionMapping) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		s.versions = versions
+		return nil
+	}
+}
+
+// WithWorkflow selects the state mappings used to compare github and jira states;
+// without it the default workflow of the default workflow file is used
+func WithWorkflow(w *workflow.Workflow) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		s.workflow = w
+		return nil
+	}
+}
+
+// WithDimensions selects the dimensions which are compared; a pair mismatches when any of them does.
+// Without it the DefaultDimensions are compared.
+func WithDimensions(dims ...Dimension) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		names := make([]string, 0, len(dims))
+		for _, d := range dims {
+			names = append(names, string(d))
+		}
+		var err error
+		s.dimensions, err = ParseDimensions(names)
+		return err
+	}
+}
+
+// WithLinkRecognizer selects the remote links which are reconciled; without it links to the issues
+// and pull requests of github.com are.  Links to hosts which the github connection doesn't serve are skipped.
+func WithLinkRecognizer(r *gh.LinkRecognizer) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		s.links = r
+		return nil
+	}
+}
+
+// WithWorkers bounds the number of concurrent requests made while reconciling
+func WithWorkers(n int) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		if n < 1 {
+			return fmt.Errorf("invalid worker count %d (must be at least 1)", n)
+		}
+		s.workers = n
+		return nil
+	}
+}
+
+// WithOrphans reports the open issues of the github project which no issue of the jira project links to
+func WithOrphans(githubProject, jiraProject string) ReconcileOption {
+	return func(s *ReconcileSpec) error {
+		if githubProject == "" || jiraProject == "" {
+			return errors.New("orphan detection requires both a github and a jira project")
+		}
+		s.reportOrphans = true
+		s.orphanGithub = githubProject
+		s.orphanJira = jiraProject
+		return nil
+	}
+}
+
+// linkRef is a github issue or pull request link found on a jira issue
+type linkRef struct {
+	jira gojira.Issue
+	url  string
+	ref  *gh.IssueRef
+	err  error // the link is malformed
+}
+
+// linkResult is the evaluation of a single linkRef
+type linkResult struct {
+	pair    PairResult
+	outcome Outcome
+}
+
+func Reconcile(ctx context.Context, jql string, jc *jira.Connection, gc *gh.Connection, options ...ReconcileOption) (*TypeResults, error) {
+	spec := &ReconcileSpec{workers: util.DefaultWorkers, dimensions: DefaultDimensions}
+	for _, opt := range options {
+		if err := opt(spec); err != nil {
+			return nil, err
+		}
+	}
+
 	results := &TypeResults{
 		Matches:    make(PairResults, 0),
 		Mismatches: make(PairResults, 0),
+		Broken:     make(PairResults, 0),
 	}
 
 	if jc == nil || gc == nil {
 		return nil, errors.New("nil connection")
 	}
 
-	// compile a regex to match github issue URLs
-	r, err := regexp.Compile(".*/github.com/.*/issues/([0-9]+)")
-	if err != nil {
-		return nil, err
+	if spec.links == nil {
+		var err error
+		spec.links, err = gh.NewLinkRecognizer()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	jiraIssues, err := jc.SearchIssues(jql)
@@ -73,98 +204,173 @@ func Reconcile(ctx context.Context, jql string, jc *jira.Connection, gc *gh.Conn
 		return nil, err
 	}
 
-	// reduce the list to just those jira which have a github issue link
-	jiraIssues = slices.DeleteFunc(jiraIssues, func(issue gojira.Issue) bool {
-		rlinks, response, err := jc.Client.Issue.GetRemoteLinksWithContext(ctx, issue.Key)
+	if spec.workflow == nil {
+		spec.workflow, err = workflow.LoadWorkflow("", "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// fetch the remote links of each jira issue once, keeping only the recognized github links for
+	// evaluation, and every link for orphan detection
+	links := make([][]linkRef, len(jiraIssues))
+	fetched := make([][]gojira.RemoteLink, len(jiraIssues))
+	err = util.ForEach(ctx, spec.workers, len(jiraIssues), func(ctx context.Context, i int) error {
+		rlinks, err := jc.GetRemoteLinks(ctx, jiraIssues[i].Key)
 		if err != nil {
-			return false
+			return fmt.Errorf("fetching remote links of %s: %v", jiraIssues[i].Key, err)
 		}
-		defer response.Body.Close()
-		if rlinks != nil {
-			found := false
-			for _, rlink := range *rlinks {
-				if r.MatchString(rlink.Object.URL) {
-					found = true
-				}
+		fetched[i] = rlinks
+		for _, rlink := range rlinks {
+			if rlink.Object == nil {
+				continue
+			}
+			ref, err := spec.links.Recognize(rlink.Object.URL)
+			if ref != nil && !gc.ServesHost(ref.Host) {
+				// the issues of another github server can't be looked up with this connection
+				continue
+			}
+			if ref != nil || err != nil {
+				links[i] = append(links[i], linkRef{jira: jiraIssues[i], url: rlink.Object.URL, ref: ref, err: err})
 			}
-			return !found
 		}
-		return false
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	// jira.PrintJiraIssues(ctx, jc, jiraIssues)
+	refs := []linkRef{}
+	for _, l := range links {
+		refs = append(refs, l...)
+	}
 
-	err = workflow.ReadWorkflows()
+	// eval status of each jira and linked github issues for mismatch; each lookup
+	// writes to its own slot so the report keeps the order of the jira search
+	evals := make([]linkResult, len(refs))
+	err = util.ForEach(ctx, spec.workers, len(refs), func(ctx context.Context, i int) error {
+		var err error
+		evals[i], err = evalLink(ctx, jc, gc, spec, refs[i])
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// eval status of each jira and linked github issues for mismatch
-	for _, ji := range jiraIssues {
-		jstat := ji.Fields.Status.Name
-		rlinks, response, err := jc.Client.Issue.GetRemoteLinksWithContext(ctx, ji.Key)
+	for _, e := range evals {
+		switch {
+		case e.pair.Broken != nil:
+			results.Broken = append(results.Broken, e.pair)
+		case e.outcome == OutcomeMatch:
+			results.Matches = append(results.Matches, e.pair)
+		default:
+			results.Mismatches = append(results.Mismatches, e.pair)
+		}
+	}
+
+	if spec.reportOrphans {
+		known := make(map[string][]gojira.RemoteLink, len(jiraIssues))
+		for i, ji := range jiraIssues {
+			known[ji.Key] = fetched[i]
+		}
+		results.Orphans, err = findOrphans(ctx, jc, gc, spec, known)
 		if err != nil {
 			return nil, err
 		}
-		defer response.Body.Close()
-		for _, rlink := range *rlinks {
-			if r.MatchString(rlink.Object.URL) {
-				project, issue, err := splitIssueRef(rlink.Object.URL)
-				if err != nil {
-					return nil, err
-				}
-				// fmt.Printf("\tproject %q issue #%d\n", project, issue)
-				gi, err := gc.GetIssue(issue, gh.WithProject(project))
-				if err != nil {
-					return nil, err
-				}
-				stateMatch, err := workflow.ValidateState(gi.GetState(), jstat)
-				if err != nil {
-					return nil, err
-				}
-				var ghAssignee string = unassigned_issue
-				if gi.GetAssignee() != nil {
-					ghAssignee = *gi.GetAssignee().Login
-				}
-				var jiAssignee string = unassigned_issue
-				if ji.Fields.Assignee != nil {
-					jiAssignee = ji.Fields.Assignee.DisplayName
-				}
-
-				pair := PairResult{
-					Jira: IssueStatus{Name: ji.Key, Status: jstat, Assignee: jiAssignee},
-					Git:  IssueStatus{Name: fmt.Sprintf("%s/%d", project, gi.GetNumber()), Status: gi.GetState(), Assignee: ghAssignee},
-				}
-				if stateMatch {
-					results.Matches = append(results.Matches, pair)
-				} else {
-					results.Mismatches = append(results.Mismatches, pair)
-				}
-			}
-		}
 	}
 
 	return results, nil
 }
 
-func splitIssueRef(ref string) (string, int, error) {
-	// split the ref into project (owner/repo), and issue number
-	s := strings.Split(ref, "/")
-	if len(s) <= 4 {
-		return "", 0, fmt.Errorf("unable to extract issue attributes from URL: %v", ref)
+// evalLink compares a jira issue with the github issue or pull request it links to; a merged pull request
+// has the "merged" state.  A bad link is reported as broken rather than failing the whole run.
+func evalLink(ctx context.Context, jc *jira.Connection, gc *gh.Connection, spec *ReconcileSpec, ref linkRef) (linkResult, error) {
+	ji := ref.jira
+	jstat := ji.Fields.Status.Name
+	jcat := jira.StatusCategory(ji.Fields.Status)
+	broken := func(name string, b *BrokenLink) linkResult {
+		return linkResult{pair: PairResult{
+			Jira:   IssueStatus{Name: ji.Key, Status: jstat, Category: jcat, Link: jc.BrowseURL(ji.Key)},
+			Git:    IssueStatus{Name: name, Link: ref.url},
+			Broken: b,
+		}}
 	}
 
-	// URLs to this point have been manicured to follow the general schema
-	// https://www.github.com/(owner/repo)/issues/(num)
-	//       project...........^^^^^^^^^^
-	//       issue number..........................^^^
-	// split on '/', their offsets into the resulting slice are
-	//                         len(s)-4  len(s)-3  len(s)-1
-	project := fmt.Sprintf("%s/%s", s[len(s)-4], s[len(s)-3])
-	num, err := strconv.Atoi(s[len(s)-1])
+	if ref.err != nil {
+		return broken(ref.url, &BrokenLink{Reason: BrokenMalformed, Message: ref.err.Error()}), nil
+	}
+	project, issue := ref.ref.Project, ref.ref.Number
+	name := fmt.Sprintf("%s/%d", project, issue)
+	gi, err := gc.GetIssueWithContext(ctx, issue, gh.WithProject(project))
 	if err != nil {
-		return "", 0, err
+		b, err := classifyGithubError(err)
+		if err != nil {
+			return linkResult{}, err
+		}
+		return broken(name, b), nil
+	}
+	if b := checkTransferred(gi, project, issue); b != nil {
+		return broken(name, b), nil
+	}
+	ghState := gi.GetState()
+	if gi.IsPullRequest() && !gi.GetPullRequestLinks().GetMergedAt().IsZero() {
+		ghState = workflow.StateMerged
+	}
+	var ghAssignee string = unassigned_issue
+	if gi.GetAssignee() != nil {
+		ghAssignee = *gi.GetAssignee().Login
+	}
+	var jiAssignee string = unassigned_issue
+	// the github login of the jira assignee, for comparison
+	var jiLogin string = unassigned_issue
+	if ji.Fields.Assignee != nil {
+		jiAssignee = ji.Fields.Assignee.DisplayName
+		jiLogin = spec.users.GithubLogin(ji.Fields.Assignee.Name, ji.Fields.Assignee.AccountID)
+	}
+	var ghLabels []string
+	for _, l := range gi.Labels {
+		ghLabels = append(ghLabels, l.GetName())
+	}
+	var fixVersions []string
+	for _, v := range ji.Fields.FixVersions {
+		fixVersions = append(fixVersions, v.Name)
 	}
 
-	return project, num, nil
+	result := linkResult{
+		pair: PairResult{
+			Jira: IssueStatus{Name: ji.Key, Title: ji.Fields.Summary, Status: jstat, Category: jcat, Assignee: jiAssignee,
+				Labels: ji.Fields.Labels, FixVersions: fixVersions, Link: jc.BrowseURL(ji.Key)},
+			Git: IssueStatus{Name: fmt.Sprintf("%s/%d", project, gi.GetNumber()), Title: gi.GetTitle(), Status: ghState, StateReason: gi.GetStateReason(),
+				Pull: gi.IsPullRequest(), Assignee: ghAssignee, Labels: ghLabels, Milestone: gi.GetMilestone().GetTitle(), Link: gi.GetHTMLURL()},
+			Outcomes: Outcomes{},
+		},
+		outcome: OutcomeMatch,
+	}
+	for _, d := range spec.dimensions {
+		var match bool
+		switch d {
+		case DimensionState:
+			match, err = spec.workflow.ValidateState(ghState, gi.GetStateReason(), workflow.JiraStatus{Name: jstat, Category: jcat})
+			if err != nil {
+				return linkResult{}, err
+			}
+		case DimensionAssignee:
+			match = strings.EqualFold(jiLogin, ghAssignee)
+		case DimensionTitle:
+			match = sameTitle(ji.Fields.Summary, gi.GetTitle())
+		case DimensionLabels:
+			match = sameLabels(ji.Fields.Labels, ghLabels)
+		case DimensionMilestone:
+			result.pair.Git.Version, err = spec.versions.JiraVersion(gi.GetMilestone().GetTitle())
+			if err != nil {
+				return linkResult{}, err
+			}
+			match = sameVersion(fixVersions, result.pair.Git.Version)
+		}
+		result.pair.Outcomes[d] = outcome(match)
+		if !match {
+			result.outcome = OutcomeMismatch
+		}
+	}
+	return result, nil
 }
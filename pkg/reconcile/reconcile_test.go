@@ -0,0 +1,186 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/v60/github"
+	"github.com/gorilla/mux"
+	ghmock "github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/gh"
+	"github.com/oceanc80/gh2jira/pkg/jira"
+	"github.com/oceanc80/gh2jira/pkg/jira/mock"
+	"github.com/oceanc80/gh2jira/pkg/workflow"
+)
+
+var testWorkflow = &workflow.Workflow{
+	Name: "test",
+	Mappings: []workflow.StateMapping{
+		{GHState: "open", JStates: []string{"To Do", "In Progress"}},
+		{GHState: "closed", JStates: []string{"Done"}},
+	},
+}
+
+// jiraIssue returns a jira issue with the given status
+func jiraIssue(key, status string) gojira.Issue {
+	return gojira.Issue{Key: key, Fields: &gojira.IssueFields{Summary: key, Status: &gojira.Status{Name: status}}}
+}
+
+// remoteLinks returns the remote links to the given URLs
+func remoteLinks(urls ...string) []gojira.RemoteLink {
+	var rlinks []gojira.RemoteLink
+	for _, u := range urls {
+		rlinks = append(rlinks, gojira.RemoteLink{Object: &gojira.RemoteLinkObject{URL: u}})
+	}
+	return rlinks
+}
+
+// newJiraConnection connects to a mocked jira answering each search query with its issues, and holding the
+// remote links of the issues; options registered first take precedence
+func newJiraConnection(t *testing.T, searches map[string][]gojira.Issue, links map[string][]gojira.RemoteLink, options ...mock.MockBackendOption) *jira.Connection {
+	options = append(options,
+		mock.WithRequestMatchHandler(mock.GetSearch, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			issues, ok := searches[r.URL.Query().Get("jql")]
+			require.True(t, ok, "unexpected search %q", r.URL.Query().Get("jql"))
+			w.Write(mock.MustMarshal(map[string]interface{}{"issues": issues, "startAt": 0, "total": len(issues)}))
+		})),
+		mock.WithRequestMatchHandler(mock.GetIssueRemoteLinksByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(mock.MustMarshal(links[mux.Vars(r)["issue"]]))
+		})),
+	)
+	jc, err := jira.NewConnection(jira.WithBaseURI("https://jira.example.com/"), jira.WithTransport(mock.NewMockedHTTPClient(options...)))
+	require.NoError(t, err)
+	require.NoError(t, jc.Connect())
+	return jc
+}
+
+// newGithubConnection connects to a mocked github
+func newGithubConnection(t *testing.T, baseURL string, options ...ghmock.MockBackendOption) *gh.Connection {
+	gc, err := gh.NewConnection(gh.WithEnterpriseURLs(baseURL, ""), gh.WithTransport(ghmock.NewMockedHTTPClient(options...)))
+	require.NoError(t, err)
+	require.NoError(t, gc.Connect())
+	return gc
+}
+
+func TestReconcile_LinkHosts(t *testing.T) {
+	searches := map[string][]gojira.Issue{"project=OPECO": {jiraIssue("OPECO-1", "To Do")}}
+	links := map[string][]gojira.RemoteLink{
+		"OPECO-1": remoteLinks(
+			"https://github.com/org/repo/issues/1",
+			"https://github.example.com/org/repo/issues/2",
+		),
+	}
+	getIssue := func(number int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write(ghmock.MustMarshal(github.Issue{
+				Number:  github.Int(number),
+				State:   github.String("open"),
+				HTMLURL: github.String(r.URL.String()),
+			}))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		github   ghmock.MockBackendOption
+		expected string
+	}{
+		{
+			name:     "github.com skips enterprise server links",
+			github:   ghmock.WithRequestMatchHandler(ghmock.GetReposIssuesByOwnerByRepoByIssueNumber, getIssue(1)),
+			expected: "org/repo/1",
+		},
+		{
+			name:    "enterprise server skips github.com links",
+			baseURL: "https://github.example.com",
+			github: ghmock.WithRequestMatchHandler(
+				ghmock.EndpointPattern{Pattern: "/api/v3/repos/{owner}/{repo}/issues/{issue_number}", Method: "GET"},
+				getIssue(2),
+			),
+			expected: "org/repo/2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recognizer, err := gh.NewLinkRecognizer(gh.WithLinkHosts("github.com", "github.example.com"))
+			require.NoError(t, err)
+
+			results, err := Reconcile(context.Background(), "project=OPECO",
+				newJiraConnection(t, searches, links), newGithubConnection(t, tt.baseURL, tt.github),
+				WithWorkflow(testWorkflow), WithDimensions(DimensionState), WithLinkRecognizer(recognizer))
+			require.NoError(t, err)
+			require.Empty(t, results.Broken)
+			require.Empty(t, results.Mismatches)
+			require.Len(t, results.Matches, 1)
+			require.Equal(t, tt.expected, results.Matches[0].Git.Name)
+		})
+	}
+}
+
+func TestReconcile_Orphans(t *testing.T) {
+	searches := map[string][]gojira.Issue{
+		"project=OPECO AND statusCategory != Done": {jiraIssue("OPECO-1", "To Do")},
+		"project=OPECO": {jiraIssue("OPECO-1", "To Do"), jiraIssue("OPECO-2", "Done")},
+	}
+	links := map[string][]gojira.RemoteLink{
+		"OPECO-1": remoteLinks("https://github.com/org/repo/issues/1"),
+		// a closed clone still claims its github issue
+		"OPECO-2": remoteLinks("https://github.com/org/repo/issues/2"),
+	}
+	var lock sync.Mutex
+	fetches := map[string]int{}
+	countFetches := mock.WithRequestMatchHandler(mock.GetIssueRemoteLinksByIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := mux.Vars(r)["issue"]
+		lock.Lock()
+		fetches[key]++
+		lock.Unlock()
+		w.Write(mock.MustMarshal(links[key]))
+	}))
+
+	open := func(number int) *github.Issue {
+		return &github.Issue{
+			Number:  github.Int(number),
+			Title:   github.String(fmt.Sprintf("issue %d", number)),
+			State:   github.String("open"),
+			HTMLURL: github.String(fmt.Sprintf("https://github.com/org/repo/issues/%d", number)),
+		}
+	}
+	gc := newGithubConnection(t, "",
+		ghmock.WithRequestMatch(ghmock.GetReposIssuesByOwnerByRepoByIssueNumber, open(1)),
+		ghmock.WithRequestMatch(ghmock.GetReposIssuesByOwnerByRepo, []*github.Issue{open(1), open(2), open(3)}),
+	)
+
+	results, err := Reconcile(context.Background(), "project=OPECO AND statusCategory != Done",
+		newJiraConnection(t, searches, links, countFetches), gc,
+		WithWorkflow(testWorkflow), WithDimensions(DimensionState), WithOrphans("org/repo", "OPECO"), WithWorkers(2))
+	require.NoError(t, err)
+	require.Len(t, results.Matches, 1)
+	require.Equal(t, []IssueStatus{{
+		Name:     "org/repo/3",
+		Title:    "issue 3",
+		Status:   "open",
+		Assignee: unassigned_issue,
+		Link:     "https://github.com/org/repo/issues/3",
+	}}, results.Orphans)
+	require.Equal(t, map[string]int{"OPECO-1": 1, "OPECO-2": 1}, fetches)
+}
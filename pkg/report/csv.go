@@ -0,0 +1,85 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+var csvHeader = []string{
+	"result", "jira", "jira_status", "jira_assignee", "jira_link",
+	"github", "github_status", "github_state_reason", "github_assignee", "github_link",
+	"state_outcome", "assignee_outcome", "title_outcome", "labels_outcome", "milestone_outcome",
+	"broken_reason", "broken_message", "title",
+}
+
+// renderCSV writes a single table with a row per pair and orphan, for spreadsheets
+func renderCSV(w io.Writer, results *reconcile.TypeResults, _ *RenderSpec) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, pair := range results.Mismatches {
+		rows = append(rows, csvPair(string(reconcile.OutcomeMismatch), pair))
+	}
+	for _, pair := range results.Matches {
+		rows = append(rows, csvPair(string(reconcile.OutcomeMatch), pair))
+	}
+	for _, pair := range results.Broken {
+		rows = append(rows, csvPair("BROKEN", pair))
+	}
+	for _, o := range results.Orphans {
+		rows = append(rows, []string{"ORPHAN", "", "", "", "", o.Name, o.Status, o.StateReason, o.Assignee, o.Link, "", "", "", "", "", "", "", o.Title})
+	}
+
+	for _, row := range rows {
+		for i := range row {
+			row[i] = csvCell(row[i])
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// csvPair writes a row for a pair; the outcome of each dimension is empty unless it was compared
+func csvPair(result string, pair reconcile.PairResult) []string {
+	reason, message := "", ""
+	if pair.Broken != nil {
+		reason, message = string(pair.Broken.Reason), pair.Broken.Message
+	}
+	row := []string{
+		result, pair.Jira.Name, pair.Jira.Status, pair.Jira.Assignee, pair.Jira.Link,
+		pair.Git.Name, pair.Git.Status, pair.Git.StateReason, pair.Git.Assignee, pair.Git.Link,
+	}
+	for _, d := range reconcile.Dimensions {
+		row = append(row, string(pair.Outcomes[d]))
+	}
+	return append(row, reason, message, pair.Git.Title)
+}
+
+// csvCell quotes a value that a spreadsheet would otherwise run as a formula; titles, assignees and labels
+// come from github and may be set by anyone
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/history"
+)
+
+// DiffFormats are the accepted formats of RenderDiff
+var DiffFormats = []Format{FormatText, FormatJSON, FormatYAML, FormatMarkdown}
+
+// ParseDiffFormat returns the diff format with the given case-insensitive name
+func ParseDiffFormat(name string) (Format, error) {
+	f := Format(strings.ToLower(name))
+	for _, df := range DiffFormats {
+		if f == df {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format %q (accepted formats are %s)", name, formatList(DiffFormats))
+}
+
+// RenderDiff writes the comparison of two reconcile snapshots to w in the given format
+func RenderDiff(w io.Writer, format Format, diff *history.Diff) error {
+	switch format {
+	case FormatText:
+		return renderDiffText(w, diff)
+	case FormatJSON:
+		return writeJSON(w, diff)
+	case FormatYAML:
+		return writeYAML(w, diff)
+	case FormatMarkdown:
+		return renderDiffMarkdown(w, diff)
+	default:
+		return fmt.Errorf("invalid output format %q (accepted formats are %s)", format, formatList(DiffFormats))
+	}
+}
+
+func diffSummary(diff *history.Diff) string {
+	return fmt.Sprintf("%v newly mismatched / %v newly fixed / %v persistently mismatched / %v broken / %v gone",
+		len(diff.NewMismatches), len(diff.Fixed), len(diff.Persistent), len(diff.Broken), len(diff.Gone))
+}
+
+func renderDiffText(w io.Writer, diff *history.Diff) error {
+	fmt.Fprintf(w, "comparing %s with %s (%s)\n", diff.To.Format(time.RFC3339), diff.From.Format(time.RFC3339), diff.JiraProject)
+	fmt.Fprintln(w, diffSummary(diff))
+
+	for _, p := range diff.NewMismatches {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\t%sNEW%s status (%q\t| %q)\n",
+			yellowStart, p.Jira.Name, p.Git.Name, colorReset, redStart, colorReset, p.Jira.Status, p.Git.QualifiedStatus())
+	}
+	for _, p := range diff.Persistent {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\t%sDRIFTING%s status (%q\t| %q) for %s (since %s)\n",
+			yellowStart, p.Jira.Name, p.Git.Name, colorReset, redStart, colorReset, p.Jira.Status, p.Git.QualifiedStatus(),
+			formatAge(p.Drifting(diff.To)), p.DriftingSince.Format(time.DateOnly))
+	}
+	for _, p := range diff.Fixed {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\t%sFIXED%s status (%q\t| %q)\n",
+			yellowStart, p.Jira.Name, p.Git.Name, colorReset, greenStart, colorReset, p.Jira.Status, p.Git.QualifiedStatus())
+	}
+	for _, p := range diff.Broken {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\t%sBROKEN%s %s: %s\n",
+			yellowStart, p.Jira.Name, p.Git.Name, colorReset, redStart, colorReset, p.Broken.Reason, p.Broken.Message)
+	}
+	for _, p := range diff.Gone {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\tGONE\n", yellowStart, p.Jira.Name, p.Git.Name, colorReset)
+	}
+	return nil
+}
+
+func renderDiffMarkdown(w io.Writer, diff *history.Diff) error {
+	fmt.Fprintf(w, "## Reconcile changes for %s\n\n", mdEscape(diff.JiraProject))
+	fmt.Fprintf(w, "_%s to %s_\n\n", diff.From.Format(time.RFC3339), diff.To.Format(time.RFC3339))
+	fmt.Fprintln(w, diffSummary(diff))
+
+	header := []string{"Jira", "Jira status", "Github", "Github status"}
+	rows := func(pairs []history.DriftPair, drifting bool) [][]string {
+		rows := make([][]string, 0, len(pairs))
+		for _, p := range pairs {
+			row := []string{mdLink(p.Jira.Name, p.Jira.Link), mdEscape(p.Jira.Status), mdLink(p.Git.Name, p.Git.Link), mdEscape(p.Git.QualifiedStatus())}
+			if drifting {
+				row = append(row, formatAge(p.Drifting(diff.To)))
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+	mdTable(w, fmt.Sprintf("Newly mismatched (%d)", len(diff.NewMismatches)), header, rows(diff.NewMismatches, false))
+	mdTable(w, fmt.Sprintf("Persistently mismatched (%d)", len(diff.Persistent)), append(header, "Drifting for"), rows(diff.Persistent, true))
+	mdTable(w, fmt.Sprintf("Newly fixed (%d)", len(diff.Fixed)), header, rows(diff.Fixed, false))
+	broken := make([][]string, 0, len(diff.Broken))
+	for _, p := range diff.Broken {
+		broken = append(broken, []string{mdLink(p.Jira.Name, p.Jira.Link), mdLink(p.Git.Name, p.Git.Link), mdEscape(string(p.Broken.Reason)), mdEscape(p.Broken.Message)})
+	}
+	mdTable(w, fmt.Sprintf("Broken (%d)", len(diff.Broken)), []string{"Jira", "Github", "Reason", "Message"}, broken)
+	mdTable(w, fmt.Sprintf("Gone (%d)", len(diff.Gone)), header, rows(diff.Gone, false))
+	return nil
+}
+
+// formatAge formats a duration in days and hours, e.g.: 14d 3h
+func formatAge(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int((d % (24 * time.Hour)) / time.Hour)
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	default:
+		return fmt.Sprintf("%dh", hours)
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/history"
+)
+
+func TestRenderDiff(t *testing.T) {
+	from := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	results := testResults()
+	diff := &history.Diff{
+		JiraProject:   "OPECO",
+		From:          from,
+		To:            to,
+		NewMismatches: []history.DriftPair{},
+		Persistent:    []history.DriftPair{{PairResult: results.Mismatches[0], DriftingSince: from.Add(-3 * time.Hour)}},
+		Fixed:         []history.DriftPair{{PairResult: results.Matches[0]}},
+		Broken:        []history.DriftPair{{PairResult: results.Broken[0]}},
+		Gone:          []history.DriftPair{},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, RenderDiff(&b, FormatMarkdown, diff))
+	require.Equal(t, `## Reconcile changes for OPECO
+
+_2024-03-01T12:00:00Z to 2024-03-08T12:00:00Z_
+
+0 newly mismatched / 1 newly fixed / 1 persistently mismatched / 1 broken / 0 gone
+
+### Newly mismatched (0)
+
+_none_
+
+### Persistently mismatched (1)
+
+| Jira | Jira status | Github | Github status | Drifting for |
+| --- | --- | --- | --- | --- |
+| [OPECO-1](https://issues.example.com/browse/OPECO-1) | In Progress | [org/repo/1](https://github.com/org/repo/issues/1) | closed (not\_planned) | 7d 3h |
+
+### Newly fixed (1)
+
+| Jira | Jira status | Github | Github status |
+| --- | --- | --- | --- |
+| [OPECO-2](https://issues.example.com/browse/OPECO-2) | Done | [org/repo/2](https://github.com/org/repo/issues/2) | closed |
+
+### Broken (1)
+
+| Jira | Github | Reason | Message |
+| --- | --- | --- | --- |
+| [OPECO-3](https://issues.example.com/browse/OPECO-3) | [org/repo/3](https://github.com/org/repo/issues/3) | not-found | github issue not found |
+
+### Gone (0)
+
+_none_
+`, b.String())
+
+	b.Reset()
+	require.NoError(t, RenderDiff(&b, FormatText, diff))
+	require.Contains(t, b.String(), "DRIFTING"+colorReset+` status ("In Progress"`+"\t| "+`"closed (not_planned)") for 7d 3h (since 2024-03-01)`)
+	require.Contains(t, b.String(), "BROKEN"+colorReset+" not-found: github issue not found")
+
+	require.EqualError(t, RenderDiff(&b, FormatHTML, diff), `invalid output format "html" (accepted formats are 'text', 'json', 'yaml', 'markdown')`)
+}
+
+func TestParseDiffFormat(t *testing.T) {
+	f, err := ParseDiffFormat("YAML")
+	require.NoError(t, err)
+	require.Equal(t, FormatYAML, f)
+
+	_, err = ParseDiffFormat("junit")
+	require.EqualError(t, err, `invalid output format "junit" (accepted formats are 'text', 'json', 'yaml', 'markdown')`)
+}
+
+func TestFormatAge(t *testing.T) {
+	require.Equal(t, "0h", formatAge(20*time.Minute))
+	require.Equal(t, "5h", formatAge(5*time.Hour))
+	require.Equal(t, "2d", formatAge(48*time.Hour))
+	require.Equal(t, "14d 1h", formatAge(337*time.Hour))
+}
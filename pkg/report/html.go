@@ -0,0 +1,146 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+// htmlData is the data of the html report template
+type htmlData struct {
+	Title       string
+	Generated   string
+	Summary     string
+	Results     *reconcile.TypeResults
+	Sections    []htmlSection
+	ShowOrphans bool
+}
+
+// htmlSection is a table of pairs
+type htmlSection struct {
+	Heading string
+	Class   string
+	Pairs   reconcile.PairResults
+}
+
+// renderHTML writes a self-contained page with sortable tables; clicking a column header sorts by it
+func renderHTML(w io.Writer, results *reconcile.TypeResults, spec *RenderSpec) error {
+	data := htmlData{
+		Title:   spec.title,
+		Summary: summary(results),
+		Results: results,
+		Sections: []htmlSection{
+			{Heading: "Mismatches", Class: "mismatch", Pairs: results.Mismatches},
+			{Heading: "Matches", Class: "match", Pairs: results.Matches},
+		},
+		ShowOrphans: showOrphans(results, spec),
+	}
+	if !spec.generated.IsZero() {
+		data.Generated = spec.generated.UTC().Format(time.RFC3339)
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"mismatched": mismatched}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+.mismatch, .no { color: #cf222e; }
+.match, .yes { color: #1a7f37; }
+.generated { color: #656d76; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{- if .Generated }}
+<p class="generated">generated {{ .Generated }}</p>
+{{- end }}
+<p>{{ .Summary }}</p>
+{{- range .Sections }}
+{{ template "pairs" . }}
+{{- end }}
+{{- if .Results.Broken }}
+<h2>Broken links ({{ len .Results.Broken }})</h2>
+<table class="sortable">
+<thead><tr><th>Jira</th><th>Jira status</th><th>Github link</th><th>Reason</th><th>Message</th></tr></thead>
+<tbody>
+{{- range .Results.Broken }}
+<tr><td><a href="{{ .Jira.Link }}">{{ .Jira.Name }}</a></td><td>{{ .Jira.Status }}</td><td><a href="{{ .Git.Link }}">{{ .Git.Name }}</a></td><td>{{ .Broken.Reason }}</td><td>{{ .Broken.Message }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+{{- if .ShowOrphans }}
+<h2>Github issues without a jira issue ({{ len .Results.Orphans }})</h2>
+{{- if .Results.Orphans }}
+<table class="sortable">
+<thead><tr><th>Github</th><th>Title</th><th>Assignee</th></tr></thead>
+<tbody>
+{{- range .Results.Orphans }}
+<tr><td><a href="{{ .Link }}">{{ .Name }}</a></td><td>{{ .Title }}</td><td>{{ .Assignee }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- else }}
+<p>none</p>
+{{- end }}
+{{- end }}
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table");
+    var tbody = table.tBodies[0];
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var asc = !th.classList.contains("asc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+    var rows = Array.prototype.slice.call(tbody.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[index].textContent.trim(), y = b.cells[index].textContent.trim();
+      var c = x.localeCompare(y, undefined, { numeric: true, sensitivity: "base" });
+      return asc ? c : -c;
+    });
+    rows.forEach(function (r) { tbody.appendChild(r); });
+  });
+});
+</script>
+</body>
+</html>
+{{ define "pairs" }}
+<h2>{{ .Heading }} ({{ len .Pairs }})</h2>
+{{- if .Pairs }}
+<table class="sortable">
+<thead><tr><th>Jira</th><th>Jira status</th><th>Github</th><th>Github status</th><th>Jira assignee</th><th>Github assignee</th><th>Mismatched</th></tr></thead>
+<tbody>
+{{- range .Pairs }}
+<tr><td><a href="{{ .Jira.Link }}">{{ .Jira.Name }}</a></td><td class="{{ $.Class }}">{{ .Jira.Status }}</td><td><a href="{{ .Git.Link }}">{{ .Git.Name }}</a></td><td class="{{ $.Class }}">{{ .Git.QualifiedStatus }}</td><td>{{ .Jira.Assignee }}</td><td>{{ .Git.Assignee }}</td><td class="no">{{ mismatched . }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- else }}
+<p>none</p>
+{{- end }}
+{{- end }}
+`))
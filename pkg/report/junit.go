@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+const junitClassName string = "gh2jira.reconcile"
+
+// renderJUnit writes JUnit XML for CI systems: each pair is a test case which fails on a mismatch,
+// broken links are errors, and orphans, when requested, are failures of their own suite
+func renderJUnit(w io.Writer, results *reconcile.TypeResults, spec *RenderSpec) error {
+	pairs := junitTestSuite{Name: spec.title}
+	if !spec.generated.IsZero() {
+		pairs.Timestamp = spec.generated.UTC().Format(time.RFC3339)
+	}
+
+	for _, pair := range results.Mismatches {
+		tc := junitPair(pair)
+		tc.Failure = &junitProblem{
+			Message: fmt.Sprintf("%s mismatch", mismatched(pair)),
+			Type:    string(reconcile.OutcomeMismatch),
+			Text:    junitMismatch(pair),
+		}
+		pairs.Cases = append(pairs.Cases, tc)
+		pairs.Failures++
+	}
+	for _, pair := range results.Broken {
+		tc := junitPair(pair)
+		tc.Error = &junitProblem{
+			Message: "broken link",
+			Type:    string(pair.Broken.Reason),
+			Text:    pair.Broken.Message,
+		}
+		pairs.Cases = append(pairs.Cases, tc)
+		pairs.Errors++
+	}
+	for _, pair := range results.Matches {
+		pairs.Cases = append(pairs.Cases, junitPair(pair))
+	}
+	pairs.Tests = len(pairs.Cases)
+
+	suites := junitTestSuites{Name: spec.title, Suites: []junitTestSuite{pairs}}
+
+	if showOrphans(results, spec) {
+		orphans := junitTestSuite{Name: spec.title + " orphans", Timestamp: pairs.Timestamp}
+		for _, o := range results.Orphans {
+			orphans.Cases = append(orphans.Cases, junitTestCase{
+				ClassName: junitClassName + ".orphans",
+				Name:      o.Name,
+				Failure: &junitProblem{
+					Message: "github issue without a jira issue",
+					Type:    "ORPHAN",
+					Text:    fmt.Sprintf("%s %q (%s)", o.Name, o.Title, o.Link),
+				},
+			})
+		}
+		orphans.Tests = len(orphans.Cases)
+		orphans.Failures = len(orphans.Cases)
+		suites.Suites = append(suites.Suites, orphans)
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// junitMismatch describes each mismatched dimension of a pair on a line of its own
+func junitMismatch(pair reconcile.PairResult) string {
+	lines := []string{}
+	for _, d := range pair.Outcomes.Mismatched() {
+		jv, gv := dimensionValues(pair, d)
+		if d == reconcile.DimensionState {
+			lines = append(lines, fmt.Sprintf("jira %s is %q but github %s is %q", pair.Jira.Name, jv, pair.Git.Name, gv))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("jira %s %s is %q but github %s %s is %q", pair.Jira.Name, d, jv, pair.Git.Name, d, gv))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func junitPair(pair reconcile.PairResult) junitTestCase {
+	return junitTestCase{
+		ClassName: junitClassName,
+		Name:      fmt.Sprintf("%s <-> %s", pair.Jira.Name, pair.Git.Name),
+		SystemOut: fmt.Sprintf("jira: %s\ngithub: %s", pair.Jira.Link, pair.Git.Link),
+	}
+}
@@ -0,0 +1,113 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+// renderMarkdown writes github flavored markdown tables, suitable for pasting into issues and pull requests
+func renderMarkdown(w io.Writer, results *reconcile.TypeResults, spec *RenderSpec) error {
+	fmt.Fprintf(w, "## %s\n\n", mdEscape(spec.title))
+	if !spec.generated.IsZero() {
+		fmt.Fprintf(w, "_generated %s_\n\n", spec.generated.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "%s\n", summary(results))
+
+	pairHeader := []string{"Jira", "Jira status", "Github", "Github status", "Jira assignee", "Github assignee", "Mismatched"}
+	mdTable(w, fmt.Sprintf("Mismatches (%d)", len(results.Mismatches)), pairHeader, mdPairRows(results.Mismatches))
+	mdTable(w, fmt.Sprintf("Matches (%d)", len(results.Matches)), pairHeader, mdPairRows(results.Matches))
+
+	if len(results.Broken) > 0 {
+		rows := make([][]string, 0, len(results.Broken))
+		for _, pair := range results.Broken {
+			rows = append(rows, []string{
+				mdLink(pair.Jira.Name, pair.Jira.Link),
+				mdEscape(pair.Jira.Status),
+				mdLink(pair.Git.Name, pair.Git.Link),
+				mdEscape(string(pair.Broken.Reason)),
+				mdEscape(pair.Broken.Message),
+			})
+		}
+		mdTable(w, fmt.Sprintf("Broken links (%d)", len(results.Broken)), []string{"Jira", "Jira status", "Github link", "Reason", "Message"}, rows)
+	}
+
+	if showOrphans(results, spec) {
+		rows := make([][]string, 0, len(results.Orphans))
+		for _, o := range results.Orphans {
+			rows = append(rows, []string{mdLink(o.Name, o.Link), mdEscape(o.Title), mdEscape(o.Assignee)})
+		}
+		mdTable(w, fmt.Sprintf("Github issues without a jira issue (%d)", len(results.Orphans)), []string{"Github", "Title", "Assignee"}, rows)
+	}
+	return nil
+}
+
+func mdPairRows(pairs reconcile.PairResults) [][]string {
+	rows := make([][]string, 0, len(pairs))
+	for _, pair := range pairs {
+		rows = append(rows, []string{
+			mdLink(pair.Jira.Name, pair.Jira.Link),
+			mdEscape(pair.Jira.Status),
+			mdLink(pair.Git.Name, pair.Git.Link),
+			mdEscape(pair.Git.QualifiedStatus()),
+			mdEscape(pair.Jira.Assignee),
+			mdEscape(pair.Git.Assignee),
+			mismatched(pair),
+		})
+	}
+	return rows
+}
+
+// mdTable writes a section with a table, or a note that the section is empty
+func mdTable(w io.Writer, heading string, header []string, rows [][]string) {
+	fmt.Fprintf(w, "\n### %s\n\n", heading)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "_none_")
+		return
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+func mdLink(text, url string) string {
+	if url == "" {
+		return mdEscape(text)
+	}
+	return fmt.Sprintf("[%s](%s)", mdEscape(text), url)
+}
+
+// mdEscape keeps cell content from breaking the table or being rendered as markup
+var mdEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", " ",
+	"\n", " ",
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
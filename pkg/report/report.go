@@ -0,0 +1,199 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+// Format names a reconcile report renderer
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatCSV      Format = "csv"
+	FormatJUnit    Format = "junit"
+)
+
+// Formats are the accepted report formats, in the order they are documented
+var Formats = []Format{FormatText, FormatJSON, FormatYAML, FormatMarkdown, FormatHTML, FormatCSV, FormatJUnit}
+
+type renderer func(w io.Writer, results *reconcile.TypeResults, spec *RenderSpec) error
+
+var renderers = map[Format]renderer{
+	FormatText:     renderText,
+	FormatJSON:     renderJSON,
+	FormatYAML:     renderYAML,
+	FormatMarkdown: renderMarkdown,
+	FormatHTML:     renderHTML,
+	FormatCSV:      renderCSV,
+	FormatJUnit:    renderJUnit,
+}
+
+// ParseFormat validates the name of a report format
+func ParseFormat(name string) (Format, error) {
+	f := Format(strings.ToLower(name))
+	if _, ok := renderers[f]; !ok {
+		return "", fmt.Errorf("invalid output format %q (accepted formats are %s)", name, formatList(Formats))
+	}
+	return f, nil
+}
+
+// IsMachineReadable reports whether the format is meant for scripts rather than people
+func (f Format) IsMachineReadable() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
+func formatList(formats []Format) string {
+	quoted := make([]string, 0, len(formats))
+	for _, f := range formats {
+		quoted = append(quoted, fmt.Sprintf("'%s'", f))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+type RenderSpec struct {
+	title     string
+	generated time.Time
+	orphans   bool
+}
+
+type RenderOption func(*RenderSpec) error
+
+// WithTitle sets the heading of markdown and html reports
+func WithTitle(title string) RenderOption {
+	return func(s *RenderSpec) error {
+		s.title = title
+		return nil
+	}
+}
+
+// WithGenerated stamps markdown and html reports with the time the results were gathered
+func WithGenerated(t time.Time) RenderOption {
+	return func(s *RenderSpec) error {
+		s.generated = t
+		return nil
+	}
+}
+
+// WithOrphans includes the orphans section even when no orphans were found
+func WithOrphans(orphans bool) RenderOption {
+	return func(s *RenderSpec) error {
+		s.orphans = orphans
+		return nil
+	}
+}
+
+// Render writes the reconcile results to w in the given format
+func Render(w io.Writer, format Format, results *reconcile.TypeResults, options ...RenderOption) error {
+	spec := &RenderSpec{title: "gh2jira reconcile report"}
+	for _, opt := range options {
+		if err := opt(spec); err != nil {
+			return err
+		}
+	}
+	r, ok := renderers[format]
+	if !ok {
+		return fmt.Errorf("invalid output format %q (accepted formats are %s)", format, formatList(Formats))
+	}
+	return r(w, results, spec)
+}
+
+func renderJSON(w io.Writer, results *reconcile.TypeResults, _ *RenderSpec) error {
+	return writeJSON(w, results)
+}
+
+func renderYAML(w io.Writer, results *reconcile.TypeResults, _ *RenderSpec) error {
+	return writeYAML(w, results)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func writeYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	yamlData, err := yaml.JSONToYAML(b)
+	if err != nil {
+		return err
+	}
+	yamlData = append([]byte("---\n"), yamlData...)
+	_, err = w.Write(yamlData)
+	return err
+}
+
+// summary is the one line overview shared by the human readable formats
+func summary(results *reconcile.TypeResults) string {
+	if len(results.Matches) == 0 && len(results.Mismatches) == 0 && len(results.Broken) == 0 {
+		return "no issues found"
+	}
+	return fmt.Sprintf("found %v mismatch / %v match / %v broken link issues", len(results.Mismatches), len(results.Matches), len(results.Broken))
+}
+
+// showOrphans reports whether the orphans section belongs in the report
+func showOrphans(results *reconcile.TypeResults, spec *RenderSpec) bool {
+	return spec.orphans || len(results.Orphans) > 0
+}
+
+// mismatched lists the mismatched dimensions of a pair, e.g.: state, assignee
+func mismatched(pair reconcile.PairResult) string {
+	names := []string{}
+	for _, d := range pair.Outcomes.Mismatched() {
+		names = append(names, string(d))
+	}
+	return strings.Join(names, ", ")
+}
+
+// dimensionValues returns the jira and github values compared for a dimension
+func dimensionValues(pair reconcile.PairResult, d reconcile.Dimension) (string, string) {
+	switch d {
+	case reconcile.DimensionState:
+		return pair.Jira.Status, pair.Git.QualifiedStatus()
+	case reconcile.DimensionAssignee:
+		return pair.Jira.Assignee, pair.Git.Assignee
+	case reconcile.DimensionTitle:
+		return pair.Jira.Title, pair.Git.Title
+	case reconcile.DimensionLabels:
+		return strings.Join(pair.Jira.Labels, ", "), strings.Join(pair.Git.Labels, ", ")
+	case reconcile.DimensionMilestone:
+		// show the version which the milestone is mapped to, if it differs
+		if version := pair.Git.Version; version != pair.Git.Milestone {
+			if version == "" {
+				version = "no version"
+			}
+			return strings.Join(pair.Jira.FixVersions, ", "), fmt.Sprintf("%s (%s)", pair.Git.Milestone, version)
+		}
+		return strings.Join(pair.Jira.FixVersions, ", "), pair.Git.Milestone
+	}
+	return "", ""
+}
@@ -0,0 +1,264 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+func testResults() *reconcile.TypeResults {
+	return &reconcile.TypeResults{
+		Mismatches: reconcile.PairResults{{
+			Jira: reconcile.IssueStatus{Name: "OPECO-1", Status: "In Progress", Assignee: "Jane Doe", Link: "https://issues.example.com/browse/OPECO-1"},
+			Git:  reconcile.IssueStatus{Name: "org/repo/1", Status: "closed", StateReason: "not_planned", Assignee: "jdoe", Link: "https://github.com/org/repo/issues/1"},
+			Outcomes: reconcile.Outcomes{
+				reconcile.DimensionState:    reconcile.OutcomeMismatch,
+				reconcile.DimensionAssignee: reconcile.OutcomeMatch,
+			},
+		}},
+		Matches: reconcile.PairResults{{
+			Jira: reconcile.IssueStatus{Name: "OPECO-2", Status: "Done", Assignee: "unassigned", Link: "https://issues.example.com/browse/OPECO-2"},
+			Git:  reconcile.IssueStatus{Name: "org/repo/2", Status: "closed", Assignee: "unassigned", Link: "https://github.com/org/repo/issues/2"},
+			Outcomes: reconcile.Outcomes{
+				reconcile.DimensionState:    reconcile.OutcomeMatch,
+				reconcile.DimensionAssignee: reconcile.OutcomeMatch,
+			},
+		}},
+		Broken: reconcile.PairResults{{
+			Jira:   reconcile.IssueStatus{Name: "OPECO-3", Status: "To Do", Link: "https://issues.example.com/browse/OPECO-3"},
+			Git:    reconcile.IssueStatus{Name: "org/repo/3", Link: "https://github.com/org/repo/issues/3"},
+			Broken: &reconcile.BrokenLink{Reason: reconcile.BrokenNotFound, Message: "github issue not found"},
+		}},
+		Orphans: []reconcile.IssueStatus{
+			{Name: "org/repo/4", Title: "crash | on <start>", Status: "open", Assignee: "unassigned", Link: "https://github.com/org/repo/issues/4"},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("Markdown")
+	require.NoError(t, err)
+	require.Equal(t, FormatMarkdown, f)
+
+	_, err = ParseFormat("pdf")
+	require.EqualError(t, err, `invalid output format "pdf" (accepted formats are 'text', 'json', 'yaml', 'markdown', 'html', 'csv', 'junit')`)
+}
+
+func TestRender_Markdown(t *testing.T) {
+	var b bytes.Buffer
+	err := Render(&b, FormatMarkdown, testResults(),
+		WithTitle("OPECO weekly"),
+		WithGenerated(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, `## OPECO weekly
+
+_generated 2024-03-01T12:00:00Z_
+
+found 1 mismatch / 1 match / 1 broken link issues
+
+### Mismatches (1)
+
+| Jira | Jira status | Github | Github status | Jira assignee | Github assignee | Mismatched |
+| --- | --- | --- | --- | --- | --- | --- |
+| [OPECO-1](https://issues.example.com/browse/OPECO-1) | In Progress | [org/repo/1](https://github.com/org/repo/issues/1) | closed (not\_planned) | Jane Doe | jdoe | state |
+
+### Matches (1)
+
+| Jira | Jira status | Github | Github status | Jira assignee | Github assignee | Mismatched |
+| --- | --- | --- | --- | --- | --- | --- |
+| [OPECO-2](https://issues.example.com/browse/OPECO-2) | Done | [org/repo/2](https://github.com/org/repo/issues/2) | closed | unassigned | unassigned |  |
+
+### Broken links (1)
+
+| Jira | Jira status | Github link | Reason | Message |
+| --- | --- | --- | --- | --- |
+| [OPECO-3](https://issues.example.com/browse/OPECO-3) | To Do | [org/repo/3](https://github.com/org/repo/issues/3) | not-found | github issue not found |
+
+### Github issues without a jira issue (1)
+
+| Github | Title | Assignee |
+| --- | --- | --- |
+| [org/repo/4](https://github.com/org/repo/issues/4) | crash \| on &lt;start&gt; | unassigned |
+`, b.String())
+}
+
+func TestRender_MarkdownEmpty(t *testing.T) {
+	var b bytes.Buffer
+	err := Render(&b, FormatMarkdown, &reconcile.TypeResults{}, WithOrphans(true))
+	require.NoError(t, err)
+	require.Equal(t, `## gh2jira reconcile report
+
+no issues found
+
+### Mismatches (0)
+
+_none_
+
+### Matches (0)
+
+_none_
+
+### Github issues without a jira issue (0)
+
+_none_
+`, b.String())
+}
+
+func TestRender_CSV(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatCSV, testResults()))
+
+	records, err := csv.NewReader(&b).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"MISMATCH", "OPECO-1", "In Progress", "Jane Doe", "https://issues.example.com/browse/OPECO-1", "org/repo/1", "closed", "not_planned", "jdoe", "https://github.com/org/repo/issues/1", "MISMATCH", "MATCH", "", "", "", "", "", ""},
+		{"MATCH", "OPECO-2", "Done", "unassigned", "https://issues.example.com/browse/OPECO-2", "org/repo/2", "closed", "", "unassigned", "https://github.com/org/repo/issues/2", "MATCH", "MATCH", "", "", "", "", "", ""},
+		{"BROKEN", "OPECO-3", "To Do", "", "https://issues.example.com/browse/OPECO-3", "org/repo/3", "", "", "", "https://github.com/org/repo/issues/3", "", "", "", "", "", "not-found", "github issue not found", ""},
+		{"ORPHAN", "", "", "", "", "org/repo/4", "open", "", "unassigned", "https://github.com/org/repo/issues/4", "", "", "", "", "", "", "", "crash | on <start>"},
+	}, records)
+}
+
+func TestRender_CSVFormulas(t *testing.T) {
+	results := &reconcile.TypeResults{
+		Orphans: []reconcile.IssueStatus{
+			{Name: "org/repo/5", Title: `=HYPERLINK("https://evil.example.com","click")`, Status: "open", Assignee: "@SUM(1+1)", Link: "https://github.com/org/repo/issues/5"},
+			{Name: "org/repo/6", Title: "-1+2", Status: "open", Assignee: "+jdoe", Link: "https://github.com/org/repo/issues/6"},
+			{Name: "org/repo/7", Title: "\tcmd", Status: "open", Assignee: "\rjdoe", Link: "https://github.com/org/repo/issues/7"},
+		},
+	}
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatCSV, results))
+
+	records, err := csv.NewReader(&b).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"ORPHAN", "", "", "", "", "org/repo/5", "open", "", "'@SUM(1+1)", "https://github.com/org/repo/issues/5", "", "", "", "", "", "", "", `'=HYPERLINK("https://evil.example.com","click")`},
+		{"ORPHAN", "", "", "", "", "org/repo/6", "open", "", "'+jdoe", "https://github.com/org/repo/issues/6", "", "", "", "", "", "", "", "'-1+2"},
+		{"ORPHAN", "", "", "", "", "org/repo/7", "open", "", "'\rjdoe", "https://github.com/org/repo/issues/7", "", "", "", "", "", "", "", "'\tcmd"},
+	}, records)
+}
+
+func TestRender_HTML(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatHTML, testResults(), WithTitle("OPECO <weekly>")))
+	page := b.String()
+
+	require.Contains(t, page, "<title>OPECO &lt;weekly&gt;</title>")
+	require.Contains(t, page, "<h2>Mismatches (1)</h2>")
+	require.Contains(t, page, `<a href="https://issues.example.com/browse/OPECO-1">OPECO-1</a>`)
+	require.Contains(t, page, `<a href="https://github.com/org/repo/issues/1">org/repo/1</a>`)
+	require.Contains(t, page, `<td class="mismatch">closed (not_planned)</td>`)
+	require.Contains(t, page, `<td>jdoe</td><td class="no">state</td>`)
+	require.Contains(t, page, "<h2>Broken links (1)</h2>")
+	require.Contains(t, page, "<td>crash | on &lt;start&gt;</td>")
+	require.Contains(t, page, `table.sortable th`)
+	require.NotContains(t, page, `<p class="generated">`)
+}
+
+func TestRender_JSON(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatJSON, testResults()))
+
+	var results reconcile.TypeResults
+	require.NoError(t, json.Unmarshal(b.Bytes(), &results))
+	require.Equal(t, *testResults(), results)
+}
+
+func TestRender_Text(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatText, testResults()))
+	text := b.String()
+
+	require.Contains(t, text, "found 1 mismatch / 1 match / 1 broken link issues\n")
+	require.Contains(t, text, "BROKEN"+colorReset+" not-found: github issue not found")
+	require.Contains(t, text, `"In Progress"`+"\t| "+`"closed (not_planned)"`)
+	require.Contains(t, text, "found 1 github issues without a jira issue\n")
+}
+
+func TestRender_JUnit(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatJUnit, testResults(),
+		WithTitle("OPECO"),
+		WithGenerated(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+	))
+	require.True(t, bytes.HasPrefix(b.Bytes(), []byte(xml.Header)))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(b.Bytes(), &suites))
+	require.Equal(t, 4, suites.Tests)
+	require.Equal(t, 2, suites.Failures)
+	require.Equal(t, 1, suites.Errors)
+	require.Len(t, suites.Suites, 2)
+
+	pairs := suites.Suites[0]
+	require.Equal(t, "OPECO", pairs.Name)
+	require.Equal(t, "2024-03-01T12:00:00Z", pairs.Timestamp)
+	require.Equal(t, 3, pairs.Tests)
+	require.Equal(t, "OPECO-1 <-> org/repo/1", pairs.Cases[0].Name)
+	require.Equal(t, "state mismatch", pairs.Cases[0].Failure.Message)
+	require.Equal(t, `jira OPECO-1 is "In Progress" but github org/repo/1 is "closed (not_planned)"`, pairs.Cases[0].Failure.Text)
+	require.Equal(t, "not-found", pairs.Cases[1].Error.Type)
+	require.Nil(t, pairs.Cases[2].Failure)
+	require.Nil(t, pairs.Cases[2].Error)
+
+	orphans := suites.Suites[1]
+	require.Equal(t, "OPECO orphans", orphans.Name)
+	require.Equal(t, 1, orphans.Failures)
+	require.Equal(t, "org/repo/4", orphans.Cases[0].Name)
+}
+
+func TestRender_Dimensions(t *testing.T) {
+	results := &reconcile.TypeResults{
+		Mismatches: reconcile.PairResults{{
+			Jira: reconcile.IssueStatus{Name: "OPECO-1", Title: "[UPSTREAM] crash on start #1", Status: "Done", Assignee: "Jane Doe", Labels: []string{"kind-bug"}, FixVersions: []string{"OSDK 1.33"}},
+			Git:  reconcile.IssueStatus{Name: "org/repo/1", Title: "crash on start", Status: "closed", Assignee: "someone", Labels: []string{"kind/bug"}, Milestone: "v1.34.2", Version: "OSDK 1.34"},
+			Outcomes: reconcile.Outcomes{
+				reconcile.DimensionState:     reconcile.OutcomeMatch,
+				reconcile.DimensionAssignee:  reconcile.OutcomeMismatch,
+				reconcile.DimensionTitle:     reconcile.OutcomeMatch,
+				reconcile.DimensionLabels:    reconcile.OutcomeMismatch,
+				reconcile.DimensionMilestone: reconcile.OutcomeMismatch,
+			},
+		}},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, Render(&b, FormatText, results))
+	text := b.String()
+	require.Contains(t, text, redStart+"assignees"+colorReset+`("Jane Doe"`+"\t| "+`"someone")`)
+	require.Contains(t, text, "\t"+greenStart+"title"+colorReset)
+	require.Contains(t, text, "\t"+redStart+"labels"+colorReset+`("kind-bug"`+"\t| "+`"kind/bug")`)
+	require.Contains(t, text, "\t"+redStart+"milestone"+colorReset+`("OSDK 1.33"`+"\t| "+`"v1.34.2 (OSDK 1.34)")`)
+
+	b.Reset()
+	require.NoError(t, Render(&b, FormatJUnit, results))
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(b.Bytes(), &suites))
+	failure := suites.Suites[0].Cases[0].Failure
+	require.Equal(t, "assignee, labels, milestone mismatch", failure.Message)
+	require.Equal(t, `jira OPECO-1 assignee is "Jane Doe" but github org/repo/1 assignee is "someone"`+"\n"+
+		`jira OPECO-1 labels is "kind-bug" but github org/repo/1 labels is "kind/bug"`+"\n"+
+		`jira OPECO-1 milestone is "OSDK 1.33" but github org/repo/1 milestone is "v1.34.2 (OSDK 1.34)"`, failure.Text)
+}
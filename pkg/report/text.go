@@ -0,0 +1,98 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/oceanc80/gh2jira/pkg/reconcile"
+)
+
+const (
+	greenStart  string = "\033[32m"
+	yellowStart string = "\033[33m"
+	redStart    string = "\033[31m"
+	colorReset  string = "\033[0m"
+)
+
+// renderText writes the colorized terminal report
+func renderText(w io.Writer, results *reconcile.TypeResults, spec *RenderSpec) error {
+	fmt.Fprintln(w, summary(results))
+
+	for _, pair := range results.Broken {
+		fmt.Fprintf(w, "%s%s|(%s)%s\n\t%sBROKEN%s %s: %s\n",
+			yellowStart, pair.Jira.Name, pair.Git.Name, colorReset, redStart, colorReset, pair.Broken.Reason, pair.Broken.Message)
+	}
+
+	for _, pair := range results.Mismatches {
+		textPair(w, pair, "MISMATCH", redStart)
+	}
+	for _, pair := range results.Matches {
+		textPair(w, pair, "MATCH", greenStart)
+	}
+	if showOrphans(results, spec) {
+		fmt.Fprintf(w, "found %v github issues without a jira issue\n", len(results.Orphans))
+		for _, o := range results.Orphans {
+			fmt.Fprintf(w, "%s%s%s\t%sORPHAN%s %q assignee(%q)\n", yellowStart, o.Name, colorReset, redStart, colorReset, o.Title, o.Assignee)
+		}
+	}
+	return nil
+}
+
+func textPair(w io.Writer, pair reconcile.PairResult, result string, resultColor string) {
+	fmt.Fprintf(w, "%s%s|(%s)%s\n\tstatus (%q\t| %q)\t%s%s%s %sassignees%s(%q\t| %q)\n",
+		yellowStart, pair.Jira.Name, pair.Git.Name, colorReset, pair.Jira.Status, pair.Git.QualifiedStatus(), resultColor, result, colorReset,
+		outcomeColor(pair, reconcile.DimensionAssignee), colorReset, pair.Jira.Assignee, pair.Git.Assignee)
+	// the optional dimensions get a line each when compared
+	for _, d := range []reconcile.Dimension{reconcile.DimensionTitle, reconcile.DimensionLabels, reconcile.DimensionMilestone} {
+		if _, ok := pair.Outcomes[d]; !ok {
+			continue
+		}
+		jv, gv := dimensionValues(pair, d)
+		fmt.Fprintf(w, "\t%s%s%s(%q\t| %q)\n", outcomeColor(pair, d), d, colorReset, jv, gv)
+	}
+}
+
+// outcomeColor colors a dimension by its outcome; dimensions which weren't compared aren't colored
+func outcomeColor(pair reconcile.PairResult, d reconcile.Dimension) string {
+	switch pair.Outcomes[d] {
+	case reconcile.OutcomeMatch:
+		return greenStart
+	case reconcile.OutcomeMismatch:
+		return redStart
+	}
+	return ""
+}
+
+// RenderFixes writes the colorized terminal report of the fixes applied, or planned in dry run mode
+func RenderFixes(w io.Writer, fixes []reconcile.FixResult, dryRun bool) {
+	if dryRun {
+		fmt.Fprintln(w, "\n############# DRY RUN MODE #############")
+	}
+	for _, f := range fixes {
+		switch {
+		case f.Error != "" && f.Applied:
+			fmt.Fprintf(w, "%s%s%s: applied %s (%q -> %q); %s%s%s\n", yellowStart, f.Issue, colorReset, f.Action, f.From, f.To, redStart, f.Error, colorReset)
+		case f.Error != "":
+			fmt.Fprintf(w, "%s%s%s: %sunable to fix: %s%s\n", yellowStart, f.Issue, colorReset, redStart, f.Error, colorReset)
+		case f.Applied:
+			fmt.Fprintf(w, "%s%s%s: %sapplied%s %s (%q -> %q)\n", yellowStart, f.Issue, colorReset, greenStart, colorReset, f.Action, f.From, f.To)
+		default:
+			fmt.Fprintf(w, "%s%s%s: would %s (%q -> %q)\n", yellowStart, f.Issue, colorReset, f.Action, f.From, f.To)
+		}
+	}
+	if dryRun {
+		fmt.Fprintln(w, "############# DRY RUN MODE #############")
+	}
+}
@@ -0,0 +1,84 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package util
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		n       int
+		failAt  int
+		wantErr bool
+	}{
+		{name: "single worker", workers: 1, n: 20, failAt: -1},
+		{name: "more items than workers", workers: 4, n: 50, failAt: -1},
+		{name: "more workers than items", workers: 16, n: 3, failAt: -1},
+		{name: "no items", workers: 4, n: 0, failAt: -1},
+		{name: "invalid worker count falls back to one", workers: 0, n: 5, failAt: -1},
+		{name: "error stops the pool", workers: 4, n: 50, failAt: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak int32
+			slots := make([]int, tt.n)
+			err := ForEach(context.Background(), tt.workers, tt.n, func(ctx context.Context, i int) error {
+				cur := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if cur <= p || atomic.CompareAndSwapInt32(&peak, p, cur) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				if i == tt.failAt {
+					return errors.New("lookup failed")
+				}
+				slots[i] = i + 1
+				return nil
+			})
+			if tt.wantErr {
+				require.EqualError(t, err, "lookup failed")
+				return
+			}
+			require.NoError(t, err)
+			require.LessOrEqual(t, int(peak), max(tt.workers, 1))
+			for i, v := range slots {
+				require.Equal(t, i+1, v)
+			}
+		})
+	}
+}
+
+func TestForEachCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	err := ForEach(ctx, 2, 100, func(ctx context.Context, i int) error {
+		if atomic.AddInt32(&calls, 1) == 5 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, int(atomic.LoadInt32(&calls)), 100)
+}
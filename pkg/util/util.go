@@ -17,12 +17,17 @@ import (
 )
 
 type FlagFeeder struct {
-	ProfilesFile  string
-	ProfileName   string
-	TokenFile     string
-	GithubProject string
-	JiraProject   string
-	JiraBaseURL   string
+	ProfilesFile     string
+	ProfileName      string
+	TokenFile        string
+	CredentialHelper string
+	GithubProject    string
+	JiraProject      string
+	JiraBaseURL      string
+	GithubBaseURL    string
+	UserMappingFile  string
+	WorkflowFile     string
+	WorkflowName     string
 }
 
 func NewFlagFeeder(c *cobra.Command) (*FlagFeeder, error) {
@@ -38,6 +43,10 @@ func NewFlagFeeder(c *cobra.Command) (*FlagFeeder, error) {
 	if err != nil {
 		return nil, err
 	}
+	credentialHelper, err := c.Flags().GetString("credential-helper")
+	if err != nil {
+		return nil, err
+	}
 	githubProject, err := c.Flags().GetString("github-project")
 	if err != nil {
 		return nil, err
@@ -46,17 +55,38 @@ func NewFlagFeeder(c *cobra.Command) (*FlagFeeder, error) {
 	if err != nil {
 		return nil, err
 	}
+	githubBaseURL, err := c.Flags().GetString("github-base-url")
+	if err != nil {
+		return nil, err
+	}
 	jiraBaseURL, err := c.Flags().GetString("jira-base-url")
 	if err != nil {
 		return nil, err
 	}
+	userMappingFile, err := c.Flags().GetString("user-mapping-file")
+	if err != nil {
+		return nil, err
+	}
+	workflowFile, err := c.Flags().GetString("workflow-file")
+	if err != nil {
+		return nil, err
+	}
+	workflowName, err := c.Flags().GetString("workflow-name")
+	if err != nil {
+		return nil, err
+	}
 
 	return &FlagFeeder{
-		ProfilesFile:  profilesFile,
-		ProfileName:   profileName,
-		TokenFile:     tokensFile,
-		GithubProject: githubProject,
-		JiraProject:   jiraProject,
-		JiraBaseURL:   jiraBaseURL,
+		ProfilesFile:     profilesFile,
+		ProfileName:      profileName,
+		TokenFile:        tokensFile,
+		CredentialHelper: credentialHelper,
+		GithubProject:    githubProject,
+		JiraProject:      jiraProject,
+		JiraBaseURL:      jiraBaseURL,
+		GithubBaseURL:    githubBaseURL,
+		UserMappingFile:  userMappingFile,
+		WorkflowFile:     workflowFile,
+		WorkflowName:     workflowName,
 	}, nil
 }
@@ -0,0 +1,256 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+type Severity string
+
+const (
+	// SeverityError problems make reconcile report wrong results
+	SeverityError Severity = "error"
+	// SeverityWarning problems are likely, but not certainly, mistakes
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a finding of Lint or Check
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func problem(severity Severity, format string, args ...any) Problem {
+	return Problem{Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// key names the mapping by its github state and reason
+func (m *StateMapping) key() string {
+	return GithubState{State: m.GHState, Reason: m.GHStateReason}.String()
+}
+
+// Lint checks the workflow for mistakes which don't depend on the statuses of a jira project
+func (w *Workflow) Lint() []Problem {
+	var problems []Problem
+
+	keys := make(map[string]bool)
+	owners := make(map[string]string) // lowercased jira state -> key of the first mapping naming it
+	for i := range w.Mappings {
+		m := &w.Mappings[i]
+		key := m.key()
+
+		switch m.GHState {
+		case "open", "closed", StateMerged:
+		default:
+			problems = append(problems, problem(SeverityError, "github state %q is never reported by github (accepted states are 'open', 'closed', 'merged')", m.GHState))
+		}
+		switch m.GHStateReason {
+		case "", "completed", "not_planned", "reopened":
+		default:
+			problems = append(problems, problem(SeverityError, "github state reason %q is never reported by github (accepted reasons are 'completed', 'not_planned', 'reopened')", m.GHStateReason))
+		}
+
+		if keys[key] {
+			problems = append(problems, problem(SeverityError, "mapping for %q is defined more than once; only the first is used", key))
+		}
+		keys[key] = true
+
+		if len(m.JStates) == 0 && len(m.JPatterns) == 0 && len(m.JRegex) == 0 && len(m.JCategories) == 0 && !m.Default {
+			problems = append(problems, problem(SeverityWarning, "mapping for %q matches no jira state", key))
+		}
+
+		named := make(map[string]bool)
+		for _, s := range m.JStates {
+			name := strings.ToLower(s)
+			if named[name] {
+				problems = append(problems, problem(SeverityWarning, "jira state %q is listed more than once for %q", s, key))
+				continue
+			}
+			named[name] = true
+			if owner, ok := owners[name]; ok {
+				problems = append(problems, problem(SeverityError, "jira state %q is mapped to both %q and %q", s, owner, key))
+				continue
+			}
+			owners[name] = key
+		}
+	}
+
+	return problems
+}
+
+// Check compares the workflow with the statuses of a jira project: mapped states which the project
+// doesn't have (usually typos) are errors; patterns matching nothing and unmapped statuses are warnings
+func (w *Workflow) Check(statuses []JiraStatus) []Problem {
+	var problems []Problem
+
+	known := make(map[string]bool)
+	for _, s := range statuses {
+		known[strings.ToLower(s.Name)] = true
+	}
+
+	for i := range w.Mappings {
+		m := &w.Mappings[i]
+		key := m.key()
+		for _, s := range m.JStates {
+			if !known[strings.ToLower(s)] {
+				problems = append(problems, problem(SeverityError, "jira state %q mapped to %q is not a status of the project", s, key))
+			}
+		}
+		for _, p := range m.JPatterns {
+			if !matchesAny(statuses, func(js JiraStatus) bool {
+				ok, _ := path.Match(strings.ToLower(p), strings.ToLower(js.Name))
+				return ok
+			}) {
+				problems = append(problems, problem(SeverityWarning, "jpattern %q mapped to %q matches no status of the project", p, key))
+			}
+		}
+		for _, r := range m.JRegex {
+			re, err := compileStatusRegex(r)
+			if err != nil {
+				continue
+			}
+			if !matchesAny(statuses, func(js JiraStatus) bool { return re.MatchString(js.Name) }) {
+				problems = append(problems, problem(SeverityWarning, "jregex %q mapped to %q matches no status of the project", r, key))
+			}
+		}
+	}
+
+	for _, s := range statuses {
+		if w.claimed(s) || w.hasDefault() {
+			continue
+		}
+		problems = append(problems, problem(SeverityWarning, "jira status %q is not mapped; its issues will always be reported as mismatches", s.Name))
+	}
+
+	return problems
+}
+
+// JiraTransition is a transition between the named statuses of a jira workflow; a transition without From
+// statuses is global and may be taken from any status
+type JiraTransition struct {
+	From []string
+	To   string
+}
+
+// CheckTransitions compares the workflow with the transitions of a jira project's workflows.  reconcile --fix
+// moves a mismatched jira issue with a single transition to a status mapped to the state of its github issue, so
+// a mapped status which reaches the statuses of another mapping only through other statuses, or not at all,
+// is a warning.
+func (w *Workflow) CheckTransitions(statuses []JiraStatus, transitions []JiraTransition) []Problem {
+	var problems []Problem
+
+	global := make(map[string]bool)
+	next := make(map[string]map[string]bool)
+	for _, t := range transitions {
+		to := strings.ToLower(t.To)
+		if len(t.From) == 0 {
+			global[to] = true
+			continue
+		}
+		for _, f := range t.From {
+			from := strings.ToLower(f)
+			if next[from] == nil {
+				next[from] = make(map[string]bool)
+			}
+			next[from][to] = true
+		}
+	}
+
+	keys := make(map[string]bool)
+	for i := range w.Mappings {
+		m := &w.Mappings[i]
+		key := m.key()
+		// merged pull requests aren't fixed, and only the first of duplicate mappings is used
+		if m.GHState == StateMerged || keys[key] {
+			continue
+		}
+		keys[key] = true
+
+		targets := make(map[string]bool)
+		for _, s := range statuses {
+			if ok, _ := w.ValidateState(m.GHState, m.GHStateReason, s); ok {
+				targets[strings.ToLower(s.Name)] = true
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		for _, s := range statuses {
+			if targets[strings.ToLower(s.Name)] {
+				continue
+			}
+			// unmapped statuses are reported by Check
+			if _, err := w.GithubState(s); err != nil {
+				continue
+			}
+			switch steps := transitionSteps(strings.ToLower(s.Name), targets, next, global); {
+			case steps < 0:
+				problems = append(problems, problem(SeverityWarning, "no transition path from jira status %q to a status mapped to %q", s.Name, key))
+			case steps > 1:
+				problems = append(problems, problem(SeverityWarning,
+					"jira status %q reaches a status mapped to %q only through %d transitions; reconcile --fix takes a single transition", s.Name, key, steps))
+			}
+		}
+	}
+
+	return problems
+}
+
+// transitionSteps returns the fewest transitions from a status to any of the targets, or -1 if none reaches them
+func transitionSteps(from string, targets map[string]bool, next map[string]map[string]bool, global map[string]bool) int {
+	seen := map[string]bool{from: true}
+	current := []string{from}
+	for steps := 1; len(current) > 0; steps++ {
+		var reached []string
+		for _, s := range current {
+			for to := range next[s] {
+				reached = append(reached, to)
+			}
+			for to := range global {
+				reached = append(reached, to)
+			}
+		}
+		current = nil
+		for _, to := range reached {
+			if targets[to] {
+				return steps
+			}
+			if !seen[to] {
+				seen[to] = true
+				current = append(current, to)
+			}
+		}
+	}
+	return -1
+}
+
+func (w *Workflow) hasDefault() bool {
+	for _, m := range w.Mappings {
+		if m.Default {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(statuses []JiraStatus, match func(JiraStatus) bool) bool {
+	for _, s := range statuses {
+		if match(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []StateMapping
+		expected []Problem
+	}{
+		{
+			name: "clean",
+			mappings: []StateMapping{
+				{GHState: "open", JStates: []string{"To Do", "In Progress"}},
+				{GHState: "closed", JStates: []string{"Done"}},
+				{GHState: "closed", GHStateReason: "not_planned", JStates: []string{"Won't Do"}},
+			},
+		},
+		{
+			name: "unknown github state and reason",
+			mappings: []StateMapping{
+				{GHState: "draft", JStates: []string{"Done"}},
+				{GHState: "closed", GHStateReason: "duplicate", JStates: []string{"Duplicate"}},
+			},
+			expected: []Problem{
+				{Severity: SeverityError, Message: `github state "draft" is never reported by github (accepted states are 'open', 'closed', 'merged')`},
+				{Severity: SeverityError, Message: `github state reason "duplicate" is never reported by github (accepted reasons are 'completed', 'not_planned', 'reopened')`},
+			},
+		},
+		{
+			name: "duplicates",
+			mappings: []StateMapping{
+				{GHState: "open", JStates: []string{"To Do", "to do", "Review"}},
+				{GHState: "closed", JStates: []string{"Done", "review"}},
+				{GHState: "closed", JStates: []string{"Verified"}},
+				{GHState: "closed", GHStateReason: "not_planned"},
+			},
+			expected: []Problem{
+				{Severity: SeverityWarning, Message: `jira state "to do" is listed more than once for "open"`},
+				{Severity: SeverityError, Message: `jira state "review" is mapped to both "open" and "closed"`},
+				{Severity: SeverityError, Message: `mapping for "closed" is defined more than once; only the first is used`},
+				{Severity: SeverityWarning, Message: `mapping for "closed (not_planned)" matches no jira state`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Workflow{Name: "jira", Mappings: tt.mappings}
+			require.Equal(t, tt.expected, w.Lint())
+		})
+	}
+}
+
+func TestWorkflow_Check(t *testing.T) {
+	statuses := []JiraStatus{
+		{Name: "To Do", Category: "To Do"},
+		{Name: "In Progress", Category: "In Progress"},
+		{Name: "Release Pending", Category: "In Progress"},
+		{Name: "Done", Category: "Done"},
+		{Name: "Obsolete", Category: "Done"},
+	}
+
+	tests := []struct {
+		name     string
+		mappings []StateMapping
+		expected []Problem
+	}{
+		{
+			name: "all statuses mapped",
+			mappings: []StateMapping{
+				{GHState: "open", JStates: []string{"to do", "In Progress"}},
+				{GHState: "closed", JPatterns: []string{"Release *"}, JCategories: []string{"Done"}},
+			},
+		},
+		{
+			name: "typos, unused patterns and unmapped statuses",
+			mappings: []StateMapping{
+				{GHState: "open", JStates: []string{"To Do", "In Progres"}},
+				{GHState: "closed", JStates: []string{"Done"}, JPatterns: []string{"Dev *"}, JRegex: []string{"Release"}},
+			},
+			expected: []Problem{
+				{Severity: SeverityError, Message: `jira state "In Progres" mapped to "open" is not a status of the project`},
+				{Severity: SeverityWarning, Message: `jpattern "Dev *" mapped to "closed" matches no status of the project`},
+				{Severity: SeverityWarning, Message: `jregex "Release" mapped to "closed" matches no status of the project`},
+				{Severity: SeverityWarning, Message: `jira status "In Progress" is not mapped; its issues will always be reported as mismatches`},
+				{Severity: SeverityWarning, Message: `jira status "Release Pending" is not mapped; its issues will always be reported as mismatches`},
+				{Severity: SeverityWarning, Message: `jira status "Obsolete" is not mapped; its issues will always be reported as mismatches`},
+			},
+		},
+		{
+			name: "default mapping claims the rest",
+			mappings: []StateMapping{
+				{GHState: "open", JStates: []string{"To Do"}, Default: true},
+				{GHState: "closed", JStates: []string{"Done"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Workflow{Name: "jira", Mappings: tt.mappings}
+			require.Equal(t, tt.expected, w.Check(statuses))
+		})
+	}
+}
+
+func TestWorkflow_CheckTransitions(t *testing.T) {
+	statuses := []JiraStatus{
+		{Name: "To Do", Category: "To Do"},
+		{Name: "In Progress", Category: "In Progress"},
+		{Name: "Review", Category: "In Progress"},
+		{Name: "Done", Category: "Done"},
+		{Name: "Obsolete", Category: "Done"},
+	}
+	mappings := []StateMapping{
+		{GHState: "open", JStates: []string{"To Do", "In Progress", "Review"}},
+		{GHState: "closed", JStates: []string{"Done"}},
+		{GHState: "closed", GHStateReason: "not_planned", JStates: []string{"Obsolete"}},
+	}
+
+	tests := []struct {
+		name        string
+		transitions []JiraTransition
+		expected    []Problem
+	}{
+		{
+			name: "every mapping reached directly",
+			transitions: []JiraTransition{
+				{From: []string{"To Do"}, To: "In Progress"},
+				{From: []string{"In Progress"}, To: "Review"},
+				{From: []string{"to do", "In Progress", "Review", "Obsolete"}, To: "Done"},
+				{From: []string{"Done", "Obsolete"}, To: "To Do"},
+				{To: "Obsolete"},
+			},
+		},
+		{
+			name: "indirect and missing paths",
+			transitions: []JiraTransition{
+				{From: []string{"To Do"}, To: "In Progress"},
+				{From: []string{"In Progress"}, To: "Review"},
+				{From: []string{"Review"}, To: "Done"},
+				{From: []string{"Done"}, To: "In Progress"},
+				{From: []string{"To Do", "In Progress", "Review"}, To: "Obsolete"},
+			},
+			expected: []Problem{
+				{Severity: SeverityWarning, Message: `no transition path from jira status "Obsolete" to a status mapped to "open"`},
+				{Severity: SeverityWarning, Message: `jira status "To Do" reaches a status mapped to "closed" only through 3 transitions; reconcile --fix takes a single transition`},
+				{Severity: SeverityWarning, Message: `jira status "In Progress" reaches a status mapped to "closed" only through 2 transitions; reconcile --fix takes a single transition`},
+				{Severity: SeverityWarning, Message: `no transition path from jira status "Obsolete" to a status mapped to "closed"`},
+				{Severity: SeverityWarning, Message: `jira status "Done" reaches a status mapped to "closed (not_planned)" only through 2 transitions; reconcile --fix takes a single transition`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Workflow{Name: "jira", Mappings: mappings}
+			require.Equal(t, tt.expected, w.CheckTransitions(statuses, tt.transitions))
+		})
+	}
+}
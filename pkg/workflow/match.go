@@ -0,0 +1,129 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// JiraStatus is a jira issue status and the name of its status category (To Do, In Progress or Done)
+type JiraStatus struct {
+	Name     string
+	Category string
+}
+
+// matchLevel ranks how specifically a mapping matches a jira status, so that
+// e.g. a status named by one mapping isn't also claimed by another mapping's category
+type matchLevel int
+
+const (
+	matchNone matchLevel = iota
+	matchCategory
+	matchPattern
+	matchName
+)
+
+// status category keys, as returned by the jira API alongside the category names
+var categoryKeys = map[string]string{
+	"new":           "to do",
+	"indeterminate": "in progress",
+	"done":          "done",
+}
+
+func normalizeCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if name, ok := categoryKeys[category]; ok {
+		return name
+	}
+	return category
+}
+
+// match returns how specifically the mapping matches the jira status; names, globs and regexes are case-insensitive
+func (m *StateMapping) match(js JiraStatus) matchLevel {
+	for _, s := range m.JStates {
+		if strings.EqualFold(s, js.Name) {
+			return matchName
+		}
+	}
+	name := strings.ToLower(js.Name)
+	for _, p := range m.JPatterns {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return matchPattern
+		}
+	}
+	for _, r := range m.JRegex {
+		if re, err := compileStatusRegex(r); err == nil && re.MatchString(js.Name) {
+			return matchPattern
+		}
+	}
+	if js.Category != "" {
+		for _, c := range m.JCategories {
+			if normalizeCategory(c) == normalizeCategory(js.Category) {
+				return matchCategory
+			}
+		}
+	}
+	return matchNone
+}
+
+// compileStatusRegex compiles a case-insensitive regex which must match the whole status name
+func compileStatusRegex(r string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)^(?:" + r + ")$")
+}
+
+// bestMatch returns the most specific match of the jira status by any mapping of the workflow, ignoring default mappings
+func (w *Workflow) bestMatch(js JiraStatus) matchLevel {
+	best := matchNone
+	for i := range w.Mappings {
+		best = max(best, w.Mappings[i].match(js))
+	}
+	return best
+}
+
+// claimed reports whether any mapping of the workflow matches the jira status, ignoring default mappings
+func (w *Workflow) claimed(js JiraStatus) bool {
+	return w.bestMatch(js) != matchNone
+}
+
+// validate checks the patterns of the workflow's mappings
+func (w *Workflow) validate() error {
+	defaults := 0
+	for _, m := range w.Mappings {
+		for _, p := range m.JPatterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("workflow %q: invalid jpattern %q: %v", w.Name, p, err)
+			}
+		}
+		for _, r := range m.JRegex {
+			if _, err := compileStatusRegex(r); err != nil {
+				return fmt.Errorf("workflow %q: invalid jregex %q: %v", w.Name, r, err)
+			}
+		}
+		for _, c := range m.JCategories {
+			switch normalizeCategory(c) {
+			case "to do", "in progress", "done":
+			default:
+				return fmt.Errorf("workflow %q: invalid jcategory %q (accepted categories are 'To Do', 'In Progress', 'Done')", w.Name, c)
+			}
+		}
+		if m.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return fmt.Errorf("workflow %q: only one mapping can be the default", w.Name)
+	}
+	return nil
+}
@@ -14,70 +14,241 @@ package workflow
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"sigs.k8s.io/yaml"
 )
 
+// StateMapping lists the jira states corresponding to a github state.
+// A mapping with a state reason (e.g. "not_planned") only applies to issues with that reason;
+// issues with other reasons fall back to the mapping of their state without a reason.
+// Besides exact names, jira states can be matched by glob pattern, by regex or by status category;
+// the default mapping claims any jira state which no mapping matches.
 type StateMapping struct {
-	GHState string   `json:"ghstate"`
-	JStates []string `json:"jstates"`
+	GHState       string   `json:"ghstate"`
+	GHStateReason string   `json:"ghstateReason,omitempty"`
+	JStates       []string `json:"jstates"`
+	JPatterns     []string `json:"jpatterns,omitempty"`
+	JRegex        []string `json:"jregex,omitempty"`
+	JCategories   []string `json:"jcategories,omitempty"`
+	Default       bool     `json:"default,omitempty"`
 }
 
-type Workflows struct {
-	Schema   string         `json:"schema"`
+// StateMerged is the state of merged pull requests, which github reports as closed.
+// Without a mapping of its own, a merged pull request is mapped as closed.
+const StateMerged string = "merged"
+
+// GithubState is a github issue state, optionally qualified by a state reason
+type GithubState struct {
+	State  string
+	Reason string
+}
+
+func (s GithubState) String() string {
+	if s.Reason == "" {
+		return s.State
+	}
+	return fmt.Sprintf("%s (%s)", s.State, s.Reason)
+}
+
+// Workflow maps github issue states to the jira statuses of a project's status scheme
+type Workflow struct {
 	Name     string         `json:"name"`
 	Mappings []StateMapping `json:"mappings"`
 }
 
-var stateMappings map[string][]string
+// Workflows is the content of a workflow file.
+// A file either holds a single workflow at the top level (name and mappings),
+// or any number of named workflows in the workflows list.
+type Workflows struct {
+	Schema    string         `json:"schema"`
+	Name      string         `json:"name,omitempty"`
+	Mappings  []StateMapping `json:"mappings,omitempty"`
+	Workflows []Workflow     `json:"workflows,omitempty"`
+}
 
-const workflowfile string = "workflows.yaml"
-const defaultWorkflow string = "jira"
+const DefaultWorkflowFile string = "workflows.yaml"
+const DefaultWorkflowName string = "jira"
 const schemaName string = "gh2jira.workflows"
 
-func ReadWorkflows() error {
-	b, err := readFile(workflowfile)
+// ReadWorkflows reads and validates a workflow file; an empty filename reads the default workflow file
+func ReadWorkflows(file string) (*Workflows, error) {
+	if file == "" {
+		file = DefaultWorkflowFile
+	}
+	b, err := readFile(file)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var ws Workflows
 	err = yaml.Unmarshal(b, &ws)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if ws.Schema != schemaName {
-		return fmt.Errorf("invalid schema: %q should be %q", ws.Schema, schemaName)
+		return nil, fmt.Errorf("invalid schema: %q should be %q", ws.Schema, schemaName)
+	}
+
+	// fold the single workflow form into the list so that both forms are looked up the same way
+	if ws.Name != "" || len(ws.Mappings) > 0 {
+		ws.Workflows = append([]Workflow{{Name: ws.Name, Mappings: ws.Mappings}}, ws.Workflows...)
+		ws.Name = ""
+		ws.Mappings = nil
+	}
+	if len(ws.Workflows) == 0 {
+		return nil, fmt.Errorf("no workflows found in %q", file)
 	}
 
-	stateMappings = make(map[string][]string)
-	if ws.Name == defaultWorkflow {
-		for _, m := range ws.Mappings {
-			stateMappings[m.GHState] = m.JStates
+	names := make(map[string]bool)
+	for _, w := range ws.Workflows {
+		if w.Name == "" {
+			return nil, fmt.Errorf("workflow without a name in %q", file)
+		}
+		if names[w.Name] {
+			return nil, fmt.Errorf("duplicate workflow %q in %q", w.Name, file)
+		}
+		names[w.Name] = true
+		if err := w.validate(); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	return &ws, nil
 }
 
-func ValidateState(ghstate string, jirastate string) (bool, error) {
+// Names returns the names of the workflows, in file order
+func (ws *Workflows) Names() []string {
+	names := make([]string, 0, len(ws.Workflows))
+	for _, w := range ws.Workflows {
+		names = append(names, w.Name)
+	}
+	return names
+}
 
-	if len(stateMappings) == 0 {
-		return false, fmt.Errorf("no state mappings found")
+// Get returns the named workflow; an empty name selects the default workflow
+func (ws *Workflows) Get(name string) (*Workflow, error) {
+	if name == "" {
+		name = DefaultWorkflowName
 	}
+	for i := range ws.Workflows {
+		if ws.Workflows[i].Name == name {
+			return &ws.Workflows[i], nil
+		}
+	}
+	return nil, fmt.Errorf("workflow %q not found (available workflows are %q)", name, ws.Names())
+}
 
-	jstates, ok := stateMappings[ghstate]
-	if !ok {
-		return false, fmt.Errorf("no state mapping found for %q", ghstate)
+// LoadWorkflow reads the workflow file and returns the named workflow, using defaults for empty values
+func LoadWorkflow(file, name string) (*Workflow, error) {
+	ws, err := ReadWorkflows(file)
+	if err != nil {
+		return nil, err
+	}
+	return ws.Get(name)
+}
+
+// ValidateState reports whether the jira status is one of those mapped to the github state and reason.
+// A status matched by several mappings belongs to the most specific match, as for GithubState.
+func (w *Workflow) ValidateState(ghstate string, ghreason string, jira JiraStatus) (bool, error) {
+	m, err := w.mapping(ghstate, ghreason)
+	if err != nil {
+		return false, err
 	}
 
-	for _, s := range jstates {
-		if s == jirastate {
-			return true, nil
+	level := m.match(jira)
+	if level == matchNone {
+		return m.Default && !w.claimed(jira), nil
+	}
+	// a status which another mapping matches more specifically belongs to that mapping
+	return level >= w.bestMatch(jira), nil
+}
+
+// JiraStates returns the jira states named by the mapping of the github state and reason, in order of preference
+func (w *Workflow) JiraStates(ghstate string, ghreason string) ([]string, error) {
+	m, err := w.mapping(ghstate, ghreason)
+	if err != nil {
+		return nil, err
+	}
+	return m.JStates, nil
+}
+
+// mapping returns the mapping of the github state and reason, falling back to the mapping of the state without a reason
+func (w *Workflow) mapping(ghstate string, ghreason string) (*StateMapping, error) {
+	if w == nil || len(w.Mappings) == 0 {
+		return nil, fmt.Errorf("no state mappings found")
+	}
+
+	var fallback *StateMapping
+	for i, m := range w.Mappings {
+		if m.GHState != ghstate {
+			continue
+		}
+		if m.GHStateReason == "" {
+			if fallback == nil {
+				fallback = &w.Mappings[i]
+			}
+			continue
+		}
+		if ghreason != "" && m.GHStateReason == ghreason {
+			return &w.Mappings[i], nil
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	if ghstate == StateMerged {
+		if m, err := w.mapping("closed", ghreason); err == nil {
+			return m, nil
+		}
+	}
+	if ghreason != "" {
+		return nil, fmt.Errorf("no state mapping found for %q", GithubState{State: ghstate, Reason: ghreason}.String())
+	}
+	return nil, fmt.Errorf("no state mapping found for %q", ghstate)
+}
+
+// GithubState returns the github state, and the state reason if the mapping names one, which corresponds to the jira state.
+// When several mappings match, the most specific match wins: names before patterns before categories before the default.
+func (w *Workflow) GithubState(jira JiraStatus) (GithubState, error) {
+	if w == nil || len(w.Mappings) == 0 {
+		return GithubState{}, fmt.Errorf("no state mappings found")
+	}
+
+	var found []GithubState
+	best := matchNone
+	for _, m := range w.Mappings {
+		level := m.match(jira)
+		if level == matchNone || level < best {
+			continue
+		}
+		if level > best {
+			best = level
+			found = nil
+		}
+		found = append(found, GithubState{State: m.GHState, Reason: m.GHStateReason})
+	}
+	if best == matchNone {
+		for _, m := range w.Mappings {
+			if m.Default {
+				found = append(found, GithubState{State: m.GHState, Reason: m.GHStateReason})
+			}
 		}
 	}
 
-	return false, nil
+	switch len(found) {
+	case 0:
+		return GithubState{}, fmt.Errorf("no state mapping found for jira state %q", jira.Name)
+	case 1:
+		return found[0], nil
+	default:
+		names := make([]string, 0, len(found))
+		for _, f := range found {
+			names = append(names, f.String())
+		}
+		sort.Strings(names)
+		return GithubState{}, fmt.Errorf("jira state %q is mapped to multiple github states %q", jira.Name, names)
+	}
 }
 
 // overrideable func for mocking os.ReadFile
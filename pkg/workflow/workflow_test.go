@@ -0,0 +1,288 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const singleWorkflow = `
+schema: gh2jira.workflows
+name: jira
+mappings:
+  - ghstate: "open"
+    jstates:
+      - "To Do"
+      - "In Progress"
+  - ghstate: "closed"
+    jstates:
+      - "Done"
+`
+
+const multipleWorkflows = `
+schema: gh2jira.workflows
+workflows:
+  - name: jira
+    mappings:
+      - ghstate: "open"
+        jstates: ["To Do"]
+      - ghstate: "closed"
+        jstates: ["Done"]
+  - name: ocpbugs
+    mappings:
+      - ghstate: "open"
+        jstates: ["New", "ASSIGNED", "POST"]
+      - ghstate: "closed"
+        jstates: ["Verified", "Closed"]
+`
+
+func TestLoadWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		file     string
+		workflow string
+		audit    func(t *testing.T, w *Workflow, err error)
+	}{
+		{
+			name:    "single workflow form with defaults",
+			content: singleWorkflow,
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "jira", w.Name)
+				require.Len(t, w.Mappings, 2)
+			},
+		},
+		{
+			name:     "named workflow from a list",
+			content:  multipleWorkflows,
+			file:     "team.yaml",
+			workflow: "ocpbugs",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "ocpbugs", w.Name)
+				jstates, err := w.JiraStates("open", "")
+				require.NoError(t, err)
+				require.Equal(t, []string{"New", "ASSIGNED", "POST"}, jstates)
+			},
+		},
+		{
+			name:     "unknown workflow",
+			content:  multipleWorkflows,
+			workflow: "agile",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `workflow "agile" not found (available workflows are ["jira" "ocpbugs"])`)
+			},
+		},
+		{
+			name:     "single workflow form is no longer limited to the jira name",
+			content:  "schema: gh2jira.workflows\nname: agile\nmappings:\n- ghstate: open\n  jstates: [Backlog]\n",
+			workflow: "agile",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "agile", w.Name)
+			},
+		},
+		{
+			name:    "invalid schema",
+			content: "schema: gh2jira.tokenstore\nname: jira\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `invalid schema: "gh2jira.tokenstore" should be "gh2jira.workflows"`)
+			},
+		},
+		{
+			name:    "no workflows",
+			content: "schema: gh2jira.workflows\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `no workflows found in "workflows.yaml"`)
+			},
+		},
+		{
+			name:    "duplicate workflow",
+			content: "schema: gh2jira.workflows\nname: jira\nworkflows:\n- name: jira\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `duplicate workflow "jira" in "workflows.yaml"`)
+			},
+		},
+		{
+			name:    "invalid regex",
+			content: "schema: gh2jira.workflows\nname: jira\nmappings:\n- ghstate: open\n  jregex: ['(In Progress']\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.ErrorContains(t, err, `workflow "jira": invalid jregex "(In Progress"`)
+			},
+		},
+		{
+			name:    "invalid glob",
+			content: "schema: gh2jira.workflows\nname: jira\nmappings:\n- ghstate: open\n  jpatterns: ['[In Progress']\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.ErrorContains(t, err, `workflow "jira": invalid jpattern "[In Progress"`)
+			},
+		},
+		{
+			name:    "invalid category",
+			content: "schema: gh2jira.workflows\nname: jira\nmappings:\n- ghstate: open\n  jcategories: [Backlog]\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.ErrorContains(t, err, `workflow "jira": invalid jcategory "Backlog"`)
+			},
+		},
+		{
+			name:    "multiple defaults",
+			content: "schema: gh2jira.workflows\nname: jira\nmappings:\n- ghstate: open\n  default: true\n- ghstate: closed\n  default: true\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `workflow "jira": only one mapping can be the default`)
+			},
+		},
+		{
+			name:    "unnamed workflow",
+			content: "schema: gh2jira.workflows\nworkflows:\n- mappings: []\n",
+			audit: func(t *testing.T, w *Workflow, err error) {
+				require.EqualError(t, err, `workflow without a name in "workflows.yaml"`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readFile = func(file string) ([]byte, error) {
+				want := tt.file
+				if want == "" {
+					want = DefaultWorkflowFile
+				}
+				require.Equal(t, want, file)
+				return []byte(tt.content), nil
+			}
+			w, err := LoadWorkflow(tt.file, tt.workflow)
+			tt.audit(t, w, err)
+		})
+	}
+}
+
+func TestWorkflowStates(t *testing.T) {
+	w := &Workflow{
+		Name: "jira",
+		Mappings: []StateMapping{
+			{GHState: "open", JStates: []string{"To Do", "In Progress", "Review"}},
+			{GHState: "closed", JStates: []string{"Done", "Review"}},
+			{GHState: "closed", GHStateReason: "not_planned", JStates: []string{"Won't Do", "Obsolete"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		ghstate   string
+		ghreason  string
+		jirastate string
+		match     bool
+		errMatch  string
+	}{
+		{name: "open matches", ghstate: "open", jirastate: "In Progress", match: true},
+		{name: "reopened falls back to open", ghstate: "open", ghreason: "reopened", jirastate: "To Do", match: true},
+		{name: "closed does not match open status", ghstate: "closed", jirastate: "In Progress"},
+		{name: "completed falls back to closed", ghstate: "closed", ghreason: "completed", jirastate: "Done", match: true},
+		{name: "not planned matches won't do", ghstate: "closed", ghreason: "not_planned", jirastate: "Won't Do", match: true},
+		{name: "not planned does not match done", ghstate: "closed", ghreason: "not_planned", jirastate: "Done"},
+		{name: "completed does not match obsolete", ghstate: "closed", ghreason: "completed", jirastate: "Obsolete"},
+		{name: "merged falls back to closed", ghstate: "merged", jirastate: "Done", match: true},
+		{name: "unmapped state", ghstate: "draft", jirastate: "Done", errMatch: `no state mapping found for "draft"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := w.ValidateState(tt.ghstate, tt.ghreason, JiraStatus{Name: tt.jirastate})
+			if tt.errMatch != "" {
+				require.EqualError(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.match, match)
+		})
+	}
+
+	ghstate, err := w.GithubState(JiraStatus{Name: "Done"})
+	require.NoError(t, err)
+	require.Equal(t, GithubState{State: "closed"}, ghstate)
+
+	ghstate, err = w.GithubState(JiraStatus{Name: "Obsolete"})
+	require.NoError(t, err)
+	require.Equal(t, GithubState{State: "closed", Reason: "not_planned"}, ghstate)
+	require.Equal(t, "closed (not_planned)", ghstate.String())
+
+	_, err = w.GithubState(JiraStatus{Name: "Review"})
+	require.EqualError(t, err, `jira state "Review" is mapped to multiple github states ["closed" "open"]`)
+
+	_, err = w.GithubState(JiraStatus{Name: "Backlog"})
+	require.EqualError(t, err, `no state mapping found for jira state "Backlog"`)
+
+	reasonOnly := &Workflow{Mappings: []StateMapping{{GHState: "closed", GHStateReason: "not_planned", JStates: []string{"Won't Do"}}}}
+	_, err = reasonOnly.JiraStates("closed", "completed")
+	require.EqualError(t, err, `no state mapping found for "closed (completed)"`)
+
+	var none *Workflow
+	_, err = none.JiraStates("open", "")
+	require.EqualError(t, err, "no state mappings found")
+
+	// merged pull requests only fall back to closed without a mapping of their own
+	pulls := &Workflow{Mappings: []StateMapping{
+		{GHState: "closed", JStates: []string{"Done"}},
+		{GHState: StateMerged, JStates: []string{"Released"}},
+	}}
+	match, err := pulls.ValidateState(StateMerged, "", JiraStatus{Name: "Released"})
+	require.NoError(t, err)
+	require.True(t, match)
+	match, err = pulls.ValidateState(StateMerged, "", JiraStatus{Name: "Done"})
+	require.NoError(t, err)
+	require.False(t, match)
+}
+
+func TestWorkflowPatterns(t *testing.T) {
+	w := &Workflow{
+		Name: "jira",
+		Mappings: []StateMapping{
+			{GHState: "open", JStates: []string{"To Do"}, JCategories: []string{"In Progress"}, Default: true},
+			{GHState: "closed", JPatterns: []string{"release *"}, JRegex: []string{"dev (complete|done)"}, JCategories: []string{"done"}},
+			{GHState: "closed", GHStateReason: "not_planned", JStates: []string{"Won't Do", "Obsolete"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		ghstate  string
+		ghreason string
+		jira     JiraStatus
+		match    bool
+		reverse  string
+	}{
+		{name: "names are case-insensitive", ghstate: "open", jira: JiraStatus{Name: "to do", Category: "To Do"}, match: true, reverse: "open"},
+		{name: "category by name", ghstate: "open", jira: JiraStatus{Name: "Code Review", Category: "In Progress"}, match: true, reverse: "open"},
+		{name: "category by key", ghstate: "closed", jira: JiraStatus{Name: "Closed", Category: "Done"}, match: true, reverse: "closed"},
+		{name: "glob", ghstate: "closed", jira: JiraStatus{Name: "Release Pending", Category: "In Progress"}, match: true, reverse: "closed"},
+		{name: "glob beats category", ghstate: "open", jira: JiraStatus{Name: "Release Pending", Category: "In Progress"}, reverse: "closed"},
+		{name: "regex must match the whole name", ghstate: "closed", jira: JiraStatus{Name: "Dev Complete", Category: "To Do"}, match: true, reverse: "closed"},
+		{name: "partial regex match", ghstate: "closed", jira: JiraStatus{Name: "Dev Complete Soon", Category: "To Do"}, reverse: "open"},
+		{name: "default claims unmapped statuses", ghstate: "open", jira: JiraStatus{Name: "Triage", Category: "To Do"}, match: true, reverse: "open"},
+		{name: "default does not claim mapped statuses", ghstate: "open", jira: JiraStatus{Name: "Verified", Category: "Done"}, reverse: "closed"},
+		{name: "name beats category", ghstate: "closed", ghreason: "completed", jira: JiraStatus{Name: "Won't Do", Category: "Done"}, reverse: "closed (not_planned)"},
+		{name: "not planned", ghstate: "closed", ghreason: "not_planned", jira: JiraStatus{Name: "obsolete", Category: "Done"}, match: true, reverse: "closed (not_planned)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := w.ValidateState(tt.ghstate, tt.ghreason, tt.jira)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, match)
+
+			ghstate, err := w.GithubState(tt.jira)
+			require.NoError(t, err)
+			require.Equal(t, tt.reverse, ghstate.String())
+		})
+	}
+}